@@ -0,0 +1,76 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/openGemini/gemix/pkg/cluster/audit"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func auditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit [audit-id]",
+		Short: "Show audit log of cluster operation",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// browsing the audit logs is not an operation to be audited
+			logger.DisableAuditLog()
+
+			switch len(args) {
+			case 0:
+				return audit.ShowAuditList(spec.AuditDir())
+			case 1:
+				return audit.ShowAuditLog(spec.AuditDir(), args[0])
+			default:
+				return cmd.Help()
+			}
+		},
+	}
+
+	cmd.AddCommand(auditCleanupCmd())
+	return cmd
+}
+
+func auditCleanupCmd() *cobra.Command {
+	var retainDays int
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "Cleanup cluster audit logs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return cmd.Help()
+			}
+
+			if retainDays < 0 {
+				return errors.Errorf("retain-days cannot be less than 0")
+			}
+
+			gOpt.DisplayMode = format
+			log.SetDisplayModeFromString(format)
+
+			return audit.DeleteAuditLog(spec.AuditDir(), retainDays, skipConfirm, gOpt.DisplayMode)
+		},
+	}
+
+	cmd.Flags().IntVar(&retainDays, "retain-days", 60, "Number of days to keep audit logs for deletion")
+	cmd.Flags().StringVar(&format, "format", "default", "(EXPERIMENTAL) The format of output, available values are [default, json]")
+	cmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
+
+	return cmd
+}
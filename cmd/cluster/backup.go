@@ -0,0 +1,89 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func backupCmd() *cobra.Command {
+	var (
+		to     string
+		onHost bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "backup <cluster-name>",
+		Short: "Backup the data of an openGemini cluster",
+		Long: `Backup the data dirs of ts-meta and ts-store, the cluster is stopped during the backup.
+The archives are pulled to the control machine, or kept on each host with --on-host, eg:
+    $ gemix cluster backup <cluster-name> --to /data/backup
+    $ gemix cluster backup <cluster-name> --to /data/backup --on-host`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			return cm.Backup(args[0], to, onHost, gOpt, skipConfirm)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "The dir to save the backup")
+	cmd.Flags().BoolVar(&onHost, "on-host", false, "Keep the backup in the dir on each host instead of pulling it to the control machine")
+	cmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func restoreCmd() *cobra.Command {
+	var from string
+
+	cmd := &cobra.Command{
+		Use:   "restore <cluster-name>",
+		Short: "Restore the data of an openGemini cluster from a backup",
+		Long: `Restore the data dirs of ts-meta and ts-store from a backup, the cluster is stopped during the restore, eg:
+    $ gemix cluster restore <cluster-name> --from /data/backup/<cluster-name>-20230801120000`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			return cm.Restore(args[0], from, gOpt, skipConfirm)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "The dir of the backup, which contains the manifest")
+	cmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"path"
+
+	"github.com/openGemini/gemix/pkg/cluster/manager"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func checkCmd() *cobra.Command {
+	opt := manager.CheckOptions{
+		IdentityFile: path.Join(utils.UserHome(), ".ssh", "id_rsa"),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "check <topology.yaml | cluster-name>",
+		Short: "Perform preflight checks for the cluster",
+		Long: `Perform preflight checks for the cluster. By default, it checks the hosts in the topology
+file before deploying, the hosts of an existing cluster are checked if a cluster name is given.
+Some of the failed items can be fixed by --apply, which requires root (or sudo) privilege.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			exist, err := openGeminiSpec.Exist(args[0])
+			if err != nil {
+				return err
+			}
+			opt.ExistCluster = exist
+
+			return cm.CheckCluster(args[0], opt, gOpt)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return nil, cobra.ShellCompDirectiveDefault
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&opt.User, "user", "u", utils.CurrentUser(), "The user name to login via SSH. The user must has root (or sudo) privilege.")
+	cmd.Flags().StringVarP(&opt.IdentityFile, "key", "k", opt.IdentityFile, "The path of the SSH identity file. If specified, public key authentication will be used.")
+	cmd.Flags().BoolVarP(&opt.UsePassword, "password", "p", false, "Use password of target hosts. If specified, password authentication will be used.")
+	cmd.Flags().BoolVar(&opt.ApplyFix, "apply", false, "Try to fix failed checks")
+
+	return cmd
+}
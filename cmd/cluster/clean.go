@@ -0,0 +1,75 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"errors"
+
+	operator "github.com/openGemini/gemix/pkg/cluster/operation"
+	"github.com/spf13/cobra"
+)
+
+func cleanCmd() *cobra.Command {
+	var cleanOpt operator.Options
+	var cleanAll bool
+
+	cmd := &cobra.Command{
+		Use:   "clean <cluster-name>",
+		Short: "(EXPERIMENTAL) Cleanup an openGemini cluster",
+		Long: `Cleanup an openGemini cluster without destroying it.
+You can retain some nodes and roles data when cleanup the cluster, eg:
+    $ gemix cluster clean <cluster-name> --all
+    $ gemix cluster clean <cluster-name> --log
+    $ gemix cluster clean <cluster-name> --data
+    $ gemix cluster clean <cluster-name> --all --ignore-role ts-meta
+    $ gemix cluster clean <cluster-name> --all --ignore-node 172.16.13.11:8092`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			if cleanAll {
+				cleanOpt.CleanupData = true
+				cleanOpt.CleanupLog = true
+				cleanOpt.CleanupAuditLog = true
+			}
+
+			if !cleanOpt.CleanupData && !cleanOpt.CleanupLog && !cleanOpt.CleanupAuditLog {
+				return errors.New("at least one of `--all` `--data` `--log` `--audit-log` should be specified")
+			}
+
+			return cm.CleanCluster(clusterName, gOpt, cleanOpt, skipConfirm)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&cleanOpt.RetainDataNodes, "ignore-node", nil, "Specify the nodes or hosts whose data will be retained")
+	cmd.Flags().StringArrayVar(&cleanOpt.RetainDataRoles, "ignore-role", nil, "Specify the roles whose data will be retained")
+	cmd.Flags().BoolVar(&cleanOpt.CleanupData, "data", false, "Cleanup data")
+	cmd.Flags().BoolVar(&cleanOpt.CleanupLog, "log", false, "Cleanup log")
+	cmd.Flags().BoolVar(&cleanOpt.CleanupAuditLog, "audit-log", false, "Cleanup the audit log of ts-sql")
+	cmd.Flags().BoolVar(&cleanAll, "all", false, "Cleanup both log and data")
+	cmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
+
+	return cmd
+}
@@ -1,17 +1,74 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
 package cluster
 
 import (
+	"strings"
+
 	"github.com/openGemini/gemix/pkg/cluster/manager"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
 	"github.com/spf13/cobra"
 )
 
+func displayCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "display <cluster-name>",
+		Short: "Display information of an openGemini cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			if err := validRoles(gOpt.Roles); err != nil {
+				return err
+			}
+
+			clusterName := args[0]
+
+			return cm.Display(clusterName, gOpt)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Only display specified roles")
+	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Only display specified nodes")
+
+	return cmd
+}
+
 func shellCompGetClusterName(cm *manager.Manager, toComplete string) ([]string, cobra.ShellCompDirective) {
 	var result []string
-	//clusters, _ := cm.GetClusterList()
-	//for _, c := range clusters {
-	//	if strings.HasPrefix(c.Name, toComplete) {
-	//		result = append(result, c.Name)
-	//	}
-	//}
+	// PersistentPreRunE is not called in shell completion, so init the manager here
+	if cm == nil {
+		if err := spec.Initialize("cluster"); err != nil {
+			return result, cobra.ShellCompDirectiveNoFileComp
+		}
+		cm = manager.NewManager("openGemini", spec.GetSpecManager(), log)
+	}
+	clusters, _ := cm.GetClusterList()
+	for _, c := range clusters {
+		if strings.HasPrefix(c.Name, toComplete) {
+			result = append(result, c.Name)
+		}
+	}
 	return result, cobra.ShellCompDirectiveNoFileComp
 }
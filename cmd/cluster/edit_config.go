@@ -0,0 +1,53 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/openGemini/gemix/pkg/cluster/manager"
+	"github.com/spf13/cobra"
+)
+
+func editConfigCmd() *cobra.Command {
+	opt := manager.EditConfigOptions{}
+	cmd := &cobra.Command{
+		Use:   "edit-config <cluster-name>",
+		Short: "Edit openGemini cluster config",
+		Long: `Edit the topology of an openGemini cluster in $EDITOR. Only the fields marked as
+editable can be changed, use reload to apply the changes to the instances.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			opt.DryRun = gOpt.DryRun
+
+			return cm.EditConfig(clusterName, opt, skipConfirm)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&opt.NewTopoFile, "from-file", "", opt.NewTopoFile, "Use the topology file instead of opening an editor")
+	cmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
+
+	return cmd
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/openGemini/gemix/pkg/cluster/manager"
+	"github.com/spf13/cobra"
+)
+
+func execCmd() *cobra.Command {
+	opt := manager.ExecOptions{}
+	var format string
+	cmd := &cobra.Command{
+		Use:   "exec <cluster-name>",
+		Short: "Run shell command on host in the openGemini cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			if err := validRoles(gOpt.Roles); err != nil {
+				return err
+			}
+
+			// the format flag is not bound to gOpt.DisplayMode, or its default
+			// would override the ones of the other commands
+			gOpt.DisplayMode = format
+			log.SetDisplayModeFromString(format)
+
+			clusterName := args[0]
+
+			return cm.Exec(clusterName, opt, gOpt)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&opt.Command, "command", "ls", "the command run on cluster host")
+	cmd.Flags().BoolVar(&opt.Sudo, "sudo", false, "use root permissions (default false)")
+	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Only exec on host with specified roles")
+	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Only exec on host with specified nodes")
+	cmd.Flags().StringVar(&format, "format", "default", "(EXPERIMENTAL) The format of output, available values are [default, json]")
+
+	return cmd
+}
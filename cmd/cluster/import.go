@@ -0,0 +1,76 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"path"
+
+	"github.com/openGemini/gemix/pkg/cluster/manager"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func importCmd() *cobra.Command {
+	var opt manager.ImportOptions
+
+	cmd := &cobra.Command{
+		Use:   "import <cluster-name>",
+		Short: "Import an openGemini cluster deployed without gemix",
+		Long: `Import an openGemini cluster deployed by hand or by install2, so that it can be managed by gemix.
+The binaries of the instances must be in <deploy_dir>/bin, the instances are not restarted, eg:
+    $ gemix cluster import <cluster-name> --from topology.yaml --version v1.1.0
+    $ gemix cluster import <cluster-name> --legacy ~/.gemix/cluster-info/<cluster-name>`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shouldContinue, err := gui.CheckCommandArgsAndMayPrintHelp(cmd, args, 1)
+			if err != nil {
+				return err
+			}
+			if !shouldContinue {
+				return nil
+			}
+
+			if opt.Version != "" {
+				if opt.Version, err = utils.FmtVer(opt.Version); err != nil {
+					return err
+				}
+			}
+			// the SSH options are read from the legacy file if not specified
+			if opt.LegacyFile == "" {
+				if opt.User == "" {
+					opt.User = utils.CurrentUser()
+				}
+				if opt.IdentityFile == "" {
+					opt.IdentityFile = path.Join(utils.UserHome(), ".ssh", "id_rsa")
+				}
+			}
+
+			return cm.Import(args[0], opt, skipConfirm, gOpt)
+		},
+	}
+
+	cmd.Flags().StringVar(&opt.TopoFile, "from", "", "The topology file describing the deployed cluster")
+	cmd.Flags().StringVar(&opt.LegacyFile, "legacy", "", "The cluster options file saved by install2")
+	cmd.Flags().StringVar(&opt.Version, "version", "", "The version of the deployed cluster, required with --from")
+	cmd.Flags().StringVarP(&opt.User, "user", "u", "", "The user name to login via SSH. The user must has root (or sudo) privilege.")
+	cmd.Flags().BoolVarP(&opt.SkipCreateUser, "skip-create-user", "", false, "Skip creating the user specified in topology.")
+	cmd.Flags().StringVarP(&opt.IdentityFile, "key", "k", "", "The path of the SSH identity file. If specified, public key authentication will be used.")
+	cmd.Flags().BoolVarP(&opt.UsePassword, "password", "p", false, "Use password of target hosts. If specified, password authentication will be used.")
+	cmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
+	cmd.MarkFlagsMutuallyExclusive("from", "legacy")
+
+	return cmd
+}
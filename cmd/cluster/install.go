@@ -73,5 +73,6 @@ func installCmd() *cobra.Command {
 	cmd.Flags().StringP("key", "k", "", "The path of the SSH identity file. If specified, public key authentication will be used.")
 	cmd.Flags().BoolVarP(&opt.UsePassword, "password", "p", false, "Use password of target hosts. If specified, password authentication will be used.")
 	cmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
+	cmd.Flags().BoolVar(&gOpt.NoRollback, "no-rollback", false, "Keep the changes on the hosts if the installation fails, so that it can be continued with --resume")
 	return cmd
 }
@@ -0,0 +1,34 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func listCmd() *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all clusters",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cm.ListCluster(format)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "table", "(EXPERIMENTAL) The format of output, available values are [table, json, yaml]")
+
+	return cmd
+}
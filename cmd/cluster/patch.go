@@ -0,0 +1,61 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/spf13/cobra"
+)
+
+func patchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "patch <cluster-name> <package-path>",
+		Short: "Replace the binary of the instances with a patch package",
+		Long: `Replace the binary of the instances with a patch package, the package is a tar.gz
+file with the binary of the component, e.g. ts-store.tar.gz containing ts-store. The current
+binaries are backed up and the patched instances are restarted one by one.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shouldContinue, err := gui.CheckCommandArgsAndMayPrintHelp(cmd, args, 2)
+			if err != nil {
+				return err
+			}
+			if !shouldContinue {
+				return nil
+			}
+
+			if err := validRoles(gOpt.Roles); err != nil {
+				return err
+			}
+
+			return cm.Patch(args[0], args[1], gOpt, skipConfirm)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			case 1:
+				return nil, cobra.ShellCompDirectiveDefault
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Specify the roles to patch")
+	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Specify the nodes to patch")
+	cmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
+
+	return cmd
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/openGemini/gemix/pkg/cluster/audit"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func replayCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay <audit-id>",
+		Short: "Replay the operation recorded in the audit log",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			if err := audit.ValidateAuditID(args[0]); err != nil {
+				return err
+			}
+			file := filepath.Join(spec.AuditDir(), args[0])
+			if utils.IsNotExist(file) {
+				return errors.Errorf("cannot find the audit log '%s'", args[0])
+			}
+
+			// the first arg is the path of the binary, like `gemix cluster start xxx`
+			args, err := audit.CommandArgs(file)
+			if err != nil {
+				return err
+			}
+			if len(args) < 3 || args[1] != ClusterCmd.Name() {
+				return errors.Errorf("the audit log '%s' is not recorded by the cluster command", file)
+			}
+			if args[2] == "replay" || args[2] == "audit" {
+				return errors.Errorf("the command `%s` cannot be replayed", strings.Join(args[1:], " "))
+			}
+
+			if !skipConfirm {
+				if err := gui.PromptForConfirmOrAbortError(
+					fmt.Sprintf("Will replay the command `%s`\nDo you want to continue? [y/N]: ",
+						color.HiYellowString("gemix %s", strings.Join(args[1:], " "))),
+				); err != nil {
+					return err
+				}
+			}
+
+			// run in a new process, or the flags of the command would be parsed into the
+			// globals such as gOpt, which are already set by the flags of replay
+			exe, err := os.Executable()
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			c := exec.Command(exe, args[1:]...)
+			c.Stdin = os.Stdin
+			c.Stdout = os.Stdout
+			c.Stderr = os.Stderr
+			if err := c.Run(); err != nil {
+				return errors.WithMessagef(err, "failed to replay the command `%s`", strings.Join(args[1:], " "))
+			}
+			return nil
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+
+	cmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
+
+	return cmd
+}
@@ -19,12 +19,17 @@ import (
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/openGemini/gemix/pkg/cluster/audit"
+	"github.com/openGemini/gemix/pkg/cluster/executor"
 	"github.com/openGemini/gemix/pkg/cluster/manager"
 	operator "github.com/openGemini/gemix/pkg/cluster/operation"
 	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/cluster/task"
 	"github.com/openGemini/gemix/pkg/gui"
 	"github.com/openGemini/gemix/pkg/logger"
 	logprinter "github.com/openGemini/gemix/pkg/logger/printer"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
@@ -34,6 +39,7 @@ var (
 	gOpt         operator.Options
 	teleTopology string //lint:ignore U1000 keep this
 	skipConfirm  bool
+	resumeID     string                     // the audit ID of the failed operation to resume
 	log          = logprinter.NewLogger("") // init default logger
 )
 
@@ -56,10 +62,29 @@ func init() {
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			log.SetDisplayModeFromString(gOpt.DisplayMode)
 
+			if err := gOpt.SSHType.Validate(); err != nil {
+				return err
+			}
 			if err := spec.Initialize("cluster"); err != nil {
 				return err
 			}
 			openGeminiSpec = spec.GetSpecManager()
+			if gOpt.DryRun {
+				dir, err := os.MkdirTemp("", "gemix-dry-run-")
+				if err != nil {
+					return errors.WithStack(err)
+				}
+				executor.EnableDryRun(dir)
+			}
+			logger.EnableAuditLog(spec.AuditDir())
+			if !gOpt.DryRun {
+				task.EnableCheckPoint(audit.CheckpointPath(spec.AuditDir(), logger.AuditID()), cmd.CommandPath(), args)
+			}
+			if resumeID != "" {
+				if err := resume(cmd, args); err != nil {
+					return err
+				}
+			}
 			cm = manager.NewManager("openGemini", openGeminiSpec, log)
 			return nil
 		},
@@ -67,21 +92,73 @@ func init() {
 
 	ClusterCmd.AddCommand(
 		templateCmd(),
+		checkCmd(),
 		installCmd(),
 		//installCmd2(),
 		startCmd(),
 		//startCmd2,
 		stopCmd(),
+		displayCmd(),
+		listCmd(),
+		scaleOutCmd(),
+		scaleInCmd(),
+		restartCmd(),
+		reloadCmd(),
+		editConfigCmd(),
+		execCmd(),
+		cleanCmd(),
+		auditCmd(),
+		replayCmd(),
 		//stopCmd2,
 		//uninstallCmd,
 		newUninstallCmd(),
-		statusCmd,
-		upgradeCmd,
+		statusCmd(),
+		upgradeCmd(),
+		patchCmd(),
+		tlsCmd(),
+		backupCmd(),
+		restoreCmd(),
+		importCmd(),
 	)
 
+	ClusterCmd.PersistentFlags().StringVar((*string)(&gOpt.SSHType), "ssh", "",
+		"The executor type: 'builtin', 'none'. If not specified, 'none' is used for loopback hosts and 'builtin' for others")
+	ClusterCmd.PersistentFlags().BoolVar(&gOpt.DryRun, "dry-run", false,
+		"Print the commands and file transfers on each host instead of performing them")
+	ClusterCmd.PersistentFlags().StringVar(&resumeID, "resume", "",
+		"Resume the failed operation of the audit ID, the tasks completed in it are skipped and nothing is rolled back on failure")
+
 	//ClusterCmd.PersistentFlags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
 }
 
+// resume skips the tasks completed in the operation of resumeID, which must
+// be recorded by the same command and arguments.
+func resume(cmd *cobra.Command, args []string) error {
+	if err := audit.ValidateAuditID(resumeID); err != nil {
+		return err
+	}
+	file := audit.CheckpointPath(spec.AuditDir(), resumeID)
+	if utils.IsNotExist(file) {
+		return errors.Errorf("cannot find the checkpoint of audit log '%s'", resumeID)
+	}
+	cp, err := task.LoadCheckPoint(file)
+	if err != nil {
+		return err
+	}
+	if cp.Command != cmd.CommandPath() || strings.Join(cp.Args, " ") != strings.Join(args, " ") {
+		return errors.Errorf("the audit log '%s' is recorded by `%s`, cannot be resumed by `%s`",
+			resumeID, strings.Join(append([]string{cp.Command}, cp.Args...), " "),
+			strings.Join(append([]string{cmd.CommandPath()}, args...), " "))
+	}
+	task.ResumeFrom(cp)
+	// the skipped tasks have nothing to undo in this process, so a partial rollback would
+	// leave their changes on the hosts without the meta, the changes are kept instead for
+	// the operation to be resumed again
+	gOpt.NoRollback = true
+	log.Infof("Resume the operation of audit log '%s', the completed tasks will be skipped and the changes are not rolled back on failure", resumeID)
+	return nil
+}
+
 // Execute executes the root command
 func Execute() {
 	zap.L().Info("Execute command", zap.String("command", strings.Join(os.Args, " ")))
@@ -93,6 +170,10 @@ func Execute() {
 		code = 1
 	}
 
+	if recorder := executor.DryRunEnabled(); recorder != nil {
+		recorder.Print(os.Stdout)
+	}
+
 	zap.L().Info("Execute command finished", zap.Int("code", code), zap.Error(err))
 
 	if err != nil {
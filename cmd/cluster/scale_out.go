@@ -0,0 +1,69 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"path"
+
+	"github.com/openGemini/gemix/pkg/cluster/manager"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func scaleOutCmd() *cobra.Command {
+	opt := manager.InstallOptions{
+		IdentityFile: path.Join(utils.UserHome(), ".ssh", "id_rsa"),
+	}
+
+	cmd := &cobra.Command{
+		Use:          "scale-out <cluster-name> <topology.yaml>",
+		Short:        "Scale out an openGemini cluster",
+		Long:         `Scale out an openGemini cluster with the new instances in the topology file. The global options and server configs of the cluster are kept.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shouldContinue, err := gui.CheckCommandArgsAndMayPrintHelp(cmd, args, 2)
+			if err != nil {
+				return err
+			}
+			if !shouldContinue {
+				return nil
+			}
+
+			clusterName := args[0]
+			topoFile := args[1]
+
+			return cm.ScaleOut(clusterName, topoFile, opt, skipConfirm, gOpt)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			case 1:
+				return nil, cobra.ShellCompDirectiveDefault
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&opt.User, "user", "u", "", "The user name to login via SSH. The user must has root (or sudo) privilege.")
+	cmd.Flags().BoolVarP(&opt.SkipCreateUser, "skip-create-user", "", false, "Skip creating the user specified in topology.")
+	cmd.Flags().StringVarP(&opt.IdentityFile, "key", "k", opt.IdentityFile, "The path of the SSH identity file. If specified, public key authentication will be used.")
+	cmd.Flags().BoolVarP(&opt.UsePassword, "password", "p", false, "Use password of target hosts. If specified, password authentication will be used.")
+	cmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
+	cmd.Flags().BoolVar(&gOpt.NoRollback, "no-rollback", false, "Keep the changes on the hosts if the scale-out fails, so that it can be continued with --resume")
+	return cmd
+}
@@ -36,13 +36,7 @@ func startCmd() *cobra.Command {
 			}
 
 			clusterName := args[0]
-			err := cm.StartCluster(clusterName, gOpt)
-			if err != nil {
-				return err
-			}
-
-			// TODO: init password
-			return nil
+			return cm.StartCluster(clusterName, gOpt, initPasswd)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			switch len(args) {
@@ -37,13 +37,6 @@ var startCmd2 = &cobra.Command{
 		}
 
 		err = StartCluster(ops)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-
-		fmt.Printf("\nCheck the status of openGemini cluster\n")
-		err = PatrolCluster(ops)
 		if err != nil {
 			fmt.Println(err)
 		}
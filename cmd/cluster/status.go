@@ -15,47 +15,46 @@
 package cluster
 
 import (
-	"fmt"
-
-	"github.com/openGemini/gemix/pkg/cluster/manager"
-	"github.com/openGemini/gemix/utils"
 	"github.com/spf13/cobra"
 )
 
-// statusCmd
-var statusCmd = &cobra.Command{
-	Use:   "status",
-	Short: "check cluster status",
-	Long:  `Check the current running status of an openGemini cluster.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		var ops utils.ClusterOptions
-		var err error
-		if ops, err = ReadClusterOptionsByName(cmd); err != nil {
-			fmt.Println(err)
-			fmt.Println(cmd.UsageString())
-			return
-		}
-
-		err = PatrolCluster(ops)
-		if err != nil {
-			fmt.Println(err)
-		}
-	},
-}
-
-func PatrolCluster(ops utils.ClusterOptions) error {
-	patroler := manager.NewGeminiStatusPatroller(ops)
-	defer patroler.Close()
-
-	if err := patroler.PrepareForPatrol(); err != nil {
-		return err
+func statusCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "status <cluster-name>",
+		Short: "Check the services, ports and disk usage of the cluster",
+		Long: `Check the current running status of an openGemini cluster on the hosts, including
+the systemd service, the listening ports and the disk usage of the data dirs of each instance.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.Help()
+			}
+
+			if err := validRoles(gOpt.Roles); err != nil {
+				return err
+			}
+
+			// the format flag is not bound to gOpt.DisplayMode, or its default
+			// would override the ones of the other commands
+			gOpt.DisplayMode = format
+			log.SetDisplayModeFromString(format)
+
+			return cm.ClusterStatus(args[0], gOpt)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
 	}
-	if err := patroler.Patrol(); err != nil {
-		return err
-	}
-	return nil
-}
 
-func init() {
-	statusCmd.Flags().StringP("name", "n", "", "cluster name")
+	cmd.Flags().StringSliceVarP(&gOpt.Roles, "role", "R", nil, "Only check specified roles")
+	cmd.Flags().StringSliceVarP(&gOpt.Nodes, "node", "N", nil, "Only check specified nodes")
+	cmd.Flags().StringVar(&format, "format", "default", "(EXPERIMENTAL) The format of output, available values are [default, json]")
+
+	return cmd
 }
@@ -0,0 +1,68 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func tlsCmd() *cobra.Command {
+	var rotateCA bool
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "tls <cluster-name> <rotate|show>",
+		Short: "Rotate or show the certificates of the TLS enabled cluster",
+		Long: `Rotate or show the certificates of the TLS enabled cluster.
+
+  rotate: re-issue the certificates of the instances and restart them one by one,
+          a new CA is generated with --ca, the previous CA is trusted until the next rotation.
+  show:   print the expiry dates of the CA, the client certificate and the instances.`,
+		ValidArgs: []string{"rotate", "show"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			switch args[1] {
+			case "rotate":
+				return cm.RotateTLS(clusterName, rotateCA, gOpt, skipConfirm)
+			case "show":
+				gOpt.DisplayMode = format
+				log.SetDisplayModeFromString(format)
+				return cm.ShowTLS(clusterName, gOpt)
+			default:
+				return cmd.Help()
+			}
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			case 1:
+				return []string{"rotate", "show"}, cobra.ShellCompDirectiveNoFileComp
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&rotateCA, "ca", false, "Generate a new CA and re-issue all the certificates with it (rotate only)")
+	cmd.Flags().StringVar(&format, "format", "default", "(EXPERIMENTAL) The format of output, available values are [default, json] (show only)")
+	cmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
+
+	return cmd
+}
@@ -15,67 +15,37 @@
 package cluster
 
 import (
-	"fmt"
-
-	"github.com/openGemini/gemix/utils"
 	"github.com/spf13/cobra"
 )
 
-var upgradeCmd = &cobra.Command{
-	Use:   "upgrade",
-	Short: "upgrade cluster",
-	Long:  `upgrade an openGemini cluster to the specified version`,
-	Run: func(cmd *cobra.Command, args []string) {
-		var ops utils.ClusterOptions
-		var err error
-		if ops, err = ReadClusterOptionsByName(cmd); err != nil {
-			fmt.Println(err)
-			fmt.Println(cmd.UsageString())
-			return
-		}
-
-		var new_version string
-		if new_version, _ = cmd.Flags().GetString("version"); new_version == "" {
-			fmt.Println("the new_version is required")
-			fmt.Println(cmd.UsageString())
-			return
-		}
-
-		err = UpgradeCluster(ops, new_version)
-		if err != nil {
-			fmt.Println(err)
-		}
-	},
-}
-
-func UpgradeCluster(ops utils.ClusterOptions, newV string) error {
-	newOps := ops
-	newOps.Version = newV
-
-	// stop all services
-	if err := StopCluster(ops); err != nil {
-		return err
+func upgradeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade <cluster-name> <version>",
+		Short: "Upgrade a specified openGemini cluster",
+		Long: `Upgrade a specified openGemini cluster in place. The binaries of all instances are backed up
+and replaced, then the instances are restarted one by one in the order of ts-meta followers,
+ts-meta leader, ts-store and ts-sql.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return cmd.Help()
+			}
+
+			clusterName := args[0]
+			version := args[1]
+
+			return cm.Upgrade(clusterName, version, gOpt, skipConfirm)
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return shellCompGetClusterName(cm, toComplete)
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
 	}
 
-	// uninstall openGeini
-	if err := UninstallCluster(ops); err != nil {
-		return err
-	}
-
-	// install new cluster
-	if err := InstallCluster(newOps); err != nil {
-		return err
-	}
-
-	// start new cluster
-	if err := StartCluster(newOps); err != nil {
-		return err
-	}
-	fmt.Printf("Successfully upgraded the openGemini cluster from %s to %s\n", ops.Version, newV)
-	return nil
-}
+	cmd.Flags().BoolVarP(&skipConfirm, "yes", "y", false, "Skip all confirmations and assumes 'yes'")
 
-func init() {
-	upgradeCmd.Flags().StringP("name", "n", "", "cluster name")
-	upgradeCmd.Flags().StringP("version", "v", "", "new cluster version")
+	return cmd
 }
@@ -0,0 +1,62 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	logprinter "github.com/openGemini/gemix/pkg/logger/printer"
+	"github.com/openGemini/gemix/pkg/playground"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var playgroundOpt = playground.Options{}
+
+// playgroundCmd represents the playground command
+var playgroundCmd = &cobra.Command{
+	Use:   "playground [version]",
+	Short: "Run a local openGemini cluster for testing",
+	Long: `Run a throwaway openGemini cluster on the local machine. The binaries are launched
+as child processes with automatically allocated ports, and the cluster is destroyed when
+Ctrl-C is pressed. Specify a tag to keep the data and reuse it next time.`,
+	Example: `  gemix playground
+  gemix playground v1.2.0 --meta 3 --store 3 --monitor
+  gemix playground --tag dev`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			version, err := utils.FmtVer(args[0])
+			if err != nil {
+				return err
+			}
+			playgroundOpt.Version = version
+		}
+		// share the package cache with the cluster component
+		if err := spec.Initialize("cluster"); err != nil {
+			return err
+		}
+		return playground.NewPlayground(playgroundOpt, logprinter.NewLogger("")).Run()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(playgroundCmd)
+	playgroundCmd.Flags().IntVar(&playgroundOpt.Meta, "meta", 1, "number of ts-meta instances")
+	playgroundCmd.Flags().IntVar(&playgroundOpt.Sql, "sql", 1, "number of ts-sql instances")
+	playgroundCmd.Flags().IntVar(&playgroundOpt.Store, "store", 1, "number of ts-store instances")
+	playgroundCmd.Flags().BoolVar(&playgroundOpt.Monitor, "monitor", false, "run ts-monitor to collect the metrics of the instances")
+	playgroundCmd.Flags().StringVar(&playgroundOpt.Tag, "tag", "", "name of the playground, the data is kept after exit if specified")
+	playgroundCmd.Flags().StringVar(&playgroundOpt.Host, "host", "127.0.0.1", "host which the instances listen on")
+}
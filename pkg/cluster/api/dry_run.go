@@ -0,0 +1,37 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/url"
+
+	"github.com/openGemini/gemix/pkg/cluster/executor"
+)
+
+// dryRun records the request to the first endpoint and returns true in dry run
+// mode, the callers should not send the request then as the operations on the
+// hosts are only recorded, e.g. the instances waited for are never started
+func dryRun(method string, endpoints []string) bool {
+	recorder := executor.DryRunEnabled()
+	if recorder == nil || len(endpoints) == 0 {
+		return false
+	}
+	host := endpoints[0]
+	if u, err := url.Parse(endpoints[0]); err == nil {
+		host = u.Hostname()
+	}
+	recorder.RecordAPI(host, method, endpoints[0])
+	return true
+}
@@ -0,0 +1,228 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	tsMetaPingURI       = "/ping"
+	tsMetaStatusURI     = "/status"
+	tsMetaStoresURI     = "/node/data"
+	tsMetaOffloadURI    = "/node/offload"
+	offloadStoreTimeout = time.Minute * 10
+)
+
+// ErrStoreNotRegistered means the ts-store is not found in the ts-store nodes of ts-meta
+var ErrStoreNotRegistered = stderrors.New("ts-store is not registered in ts-meta")
+
+// TSMetaClient is an HTTP client of the ts-meta server
+type TSMetaClient struct {
+	addrs      []string
+	tlsEnabled bool
+	httpClient *utils.HTTPClient
+	ctx        context.Context
+}
+
+// NewTSMetaClient returns a new TSMetaClient, the addrs are the http addresses of ts-meta
+func NewTSMetaClient(ctx context.Context, addrs []string, timeout time.Duration, tlsConfig *tls.Config) *TSMetaClient {
+	return &TSMetaClient{
+		addrs:      addrs,
+		tlsEnabled: tlsConfig != nil,
+		httpClient: utils.NewHTTPClient(timeout, tlsConfig),
+		ctx:        ctx,
+	}
+}
+
+// TSMetaStatus is the status of a ts-meta node
+type TSMetaStatus struct {
+	NodeType string   `json:"nodeType"`
+	Leader   string   `json:"leader"`
+	HTTPAddr string   `json:"httpAddr"`
+	RaftAddr string   `json:"raftAddr"`
+	Peers    []string `json:"peers"`
+}
+
+// TSStoreNode is a ts-store node registered in ts-meta
+type TSStoreNode struct {
+	ID      uint64 `json:"id"`
+	Host    string `json:"host"`
+	TCPHost string `json:"tcpHost"`
+	Status  string `json:"status"`
+	PtNum   int    `json:"ptNum"`
+}
+
+func (mc *TSMetaClient) getEndpoints(uri string) (endpoints []string) {
+	scheme := "http"
+	if mc.tlsEnabled {
+		scheme = "https"
+	}
+	for _, addr := range mc.addrs {
+		endpoints = append(endpoints, fmt.Sprintf("%s://%s%s", scheme, addr, uri))
+	}
+	return
+}
+
+// tryEndpoints requests the endpoints one by one until one of them succeeds
+func (mc *TSMetaClient) tryEndpoints(endpoints []string, fn func(endpoint string) error) error {
+	if len(endpoints) == 0 {
+		return errors.New("no ts-meta address is specified")
+	}
+	var err error
+	for _, endpoint := range endpoints {
+		if err = fn(endpoint); err == nil {
+			return nil
+		}
+	}
+	return errors.WithMessagef(err, "failed to request ts-meta %v", endpoints)
+}
+
+// Ping checks the health of the first available ts-meta node
+func (mc *TSMetaClient) Ping() error {
+	return mc.tryEndpoints(mc.getEndpoints(tsMetaPingURI), func(endpoint string) error {
+		_, err := mc.httpClient.Get(mc.ctx, endpoint)
+		return err
+	})
+}
+
+// WaitReady waits until the ping api of ts-meta is available, it succeeds at
+// once in dry run mode
+func (mc *TSMetaClient) WaitReady(timeout time.Duration) error {
+	if dryRun(http.MethodGet, mc.getEndpoints(tsMetaPingURI)) {
+		return nil
+	}
+	return utils.Retry(mc.Ping, utils.RetryOption{
+		Delay:   time.Second,
+		Timeout: timeout,
+	})
+}
+
+// GetStatus queries the status of the first available ts-meta node
+func (mc *TSMetaClient) GetStatus() (*TSMetaStatus, error) {
+	status := &TSMetaStatus{}
+	err := mc.tryEndpoints(mc.getEndpoints(tsMetaStatusURI), func(endpoint string) error {
+		body, err := mc.httpClient.Get(mc.ctx, endpoint)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(body, status)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// GetLeader queries the raft leader address of the ts-meta cluster
+func (mc *TSMetaClient) GetLeader() (string, error) {
+	status, err := mc.GetStatus()
+	if err != nil {
+		return "", err
+	}
+	if status.Leader == "" {
+		return "", errors.New("ts-meta leader not found")
+	}
+	return status.Leader, nil
+}
+
+// GetStores queries the ts-store nodes registered in ts-meta
+func (mc *TSMetaClient) GetStores() ([]TSStoreNode, error) {
+	var stores []TSStoreNode
+	err := mc.tryEndpoints(mc.getEndpoints(tsMetaStoresURI), func(endpoint string) error {
+		body, err := mc.httpClient.Get(mc.ctx, endpoint)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(body, &stores)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stores, nil
+}
+
+// OffloadStore asks ts-meta to migrate the PTs of the ts-store to the other nodes,
+// the addr is the select address of the ts-store, the request is only recorded in dry run mode
+func (mc *TSMetaClient) OffloadStore(addr string) error {
+	uri := fmt.Sprintf("%s?host=%s", tsMetaOffloadURI, url.QueryEscape(addr))
+	if dryRun(http.MethodPost, mc.getEndpoints(uri)) {
+		return nil
+	}
+	return mc.tryEndpoints(mc.getEndpoints(uri), func(endpoint string) error {
+		_, err := mc.httpClient.Post(mc.ctx, endpoint, nil)
+		return err
+	})
+}
+
+// IsStoreOffloaded checks whether the ts-store matched by match has no PT left,
+// ErrStoreNotRegistered is returned if no ts-store registered in ts-meta matches
+func (mc *TSMetaClient) IsStoreOffloaded(match func(addr string) bool) (bool, error) {
+	stores, err := mc.GetStores()
+	if err != nil {
+		return false, err
+	}
+	for _, store := range stores {
+		if match(store.TCPHost) || match(store.Host) {
+			return store.PtNum == 0, nil
+		}
+	}
+	return false, errors.WithStack(ErrStoreNotRegistered)
+}
+
+// WaitStoreOffloaded waits until all the PTs of the ts-store matched by match are
+// migrated, it fails at once if the ts-store is not registered in ts-meta, and it
+// succeeds at once in dry run mode as the offloading request is not sent
+func (mc *TSMetaClient) WaitStoreOffloaded(match func(addr string) bool, retryOpt *utils.RetryOption) error {
+	if dryRun(http.MethodGet, mc.getEndpoints(tsMetaStoresURI)) {
+		return nil
+	}
+	if retryOpt == nil {
+		retryOpt = &utils.RetryOption{
+			Delay:   time.Second * 5,
+			Timeout: offloadStoreTimeout,
+		}
+	}
+	var notRegistered error
+	err := utils.Retry(func() error {
+		offloaded, err := mc.IsStoreOffloaded(match)
+		if stderrors.Is(err, ErrStoreNotRegistered) {
+			// stop retrying, the PTs of an unknown store cannot be tracked
+			notRegistered = err
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !offloaded {
+			return errors.New("the PTs of ts-store are still being migrated")
+		}
+		return nil
+	}, *retryOpt)
+	if notRegistered != nil {
+		return notRegistered
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTSStoreOffloaded(t *testing.T) {
+	var stores string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/node/data" {
+			fmt.Fprint(w, stores)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewTSMetaClient(context.Background(), []string{strings.TrimPrefix(srv.URL, "http://")}, time.Second, nil)
+	match := func(addr string) bool {
+		return addr == "172.16.5.140:8401"
+	}
+	retryOpt := &utils.RetryOption{Delay: time.Millisecond * 10, Timeout: time.Millisecond * 100}
+
+	// not found
+	stores = `[{"id":1,"host":"172.16.5.141:8400","tcpHost":"172.16.5.141:8401","ptNum":0}]`
+	_, err := client.IsStoreOffloaded(match)
+	assert.ErrorIs(t, err, ErrStoreNotRegistered)
+	assert.ErrorIs(t, client.WaitStoreOffloaded(match, retryOpt), ErrStoreNotRegistered)
+
+	// PTs not migrated
+	stores = `[{"id":1,"host":"172.16.5.140:8400","tcpHost":"172.16.5.140:8401","ptNum":2}]`
+	offloaded, err := client.IsStoreOffloaded(match)
+	assert.NoError(t, err)
+	assert.False(t, offloaded)
+	assert.Error(t, client.WaitStoreOffloaded(match, retryOpt))
+
+	// offloaded
+	stores = `[{"id":1,"host":"172.16.5.140:8400","tcpHost":"172.16.5.140:8401","ptNum":0}]`
+	offloaded, err = client.IsStoreOffloaded(match)
+	assert.NoError(t, err)
+	assert.True(t, offloaded)
+	assert.NoError(t, client.WaitStoreOffloaded(match, retryOpt))
+}
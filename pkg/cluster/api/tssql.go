@@ -0,0 +1,142 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	tsSqlPingURI  = "/ping"
+	tsSqlQueryURI = "/query"
+)
+
+// TSSqlClient is an HTTP client of the ts-sql server
+type TSSqlClient struct {
+	addrs      []string
+	tlsEnabled bool
+	httpClient *utils.HTTPClient
+	ctx        context.Context
+}
+
+// NewTSSqlClient returns a new TSSqlClient, the addrs are the http addresses of ts-sql
+func NewTSSqlClient(ctx context.Context, addrs []string, timeout time.Duration, tlsConfig *tls.Config) *TSSqlClient {
+	httpClient := utils.NewHTTPClient(timeout, tlsConfig)
+	httpClient.SetRequestHeader("Content-Type", "application/x-www-form-urlencoded")
+	return &TSSqlClient{
+		addrs:      addrs,
+		tlsEnabled: tlsConfig != nil,
+		httpClient: httpClient,
+		ctx:        ctx,
+	}
+}
+
+// queryResponse is the response of the query api
+type queryResponse struct {
+	Results []struct {
+		Error string `json:"error,omitempty"`
+	} `json:"results"`
+	Error string `json:"error,omitempty"`
+}
+
+func (sc *TSSqlClient) getEndpoints(uri string) (endpoints []string) {
+	scheme := "http"
+	if sc.tlsEnabled {
+		scheme = "https"
+	}
+	for _, addr := range sc.addrs {
+		endpoints = append(endpoints, fmt.Sprintf("%s://%s%s", scheme, addr, uri))
+	}
+	return
+}
+
+// Ping checks the health of the first available ts-sql node
+func (sc *TSSqlClient) Ping() error {
+	endpoints := sc.getEndpoints(tsSqlPingURI)
+	if len(endpoints) == 0 {
+		return errors.New("no ts-sql address is specified")
+	}
+	var err error
+	for _, endpoint := range endpoints {
+		if _, err = sc.httpClient.Get(sc.ctx, endpoint); err == nil {
+			return nil
+		}
+	}
+	return errors.WithMessagef(err, "failed to request ts-sql %v", endpoints)
+}
+
+// WaitReady waits until the ping api of ts-sql is available, it succeeds at
+// once in dry run mode
+func (sc *TSSqlClient) WaitReady(timeout time.Duration) error {
+	if dryRun(http.MethodGet, sc.getEndpoints(tsSqlPingURI)) {
+		return nil
+	}
+	return utils.Retry(sc.Ping, utils.RetryOption{
+		Delay:   time.Second,
+		Timeout: timeout,
+	})
+}
+
+// Query executes the statement on the first available ts-sql node, the statement
+// is sent in the request body so that it's not recorded in the access log, and
+// it's only recorded without the statement in dry run mode
+func (sc *TSSqlClient) Query(stmt string) error {
+	endpoints := sc.getEndpoints(tsSqlQueryURI)
+	if len(endpoints) == 0 {
+		return errors.New("no ts-sql address is specified")
+	}
+	if dryRun(http.MethodPost, endpoints) {
+		return nil
+	}
+
+	body := url.Values{"q": []string{stmt}}.Encode()
+	var err error
+	for _, endpoint := range endpoints {
+		var data []byte
+		if data, err = sc.httpClient.Post(sc.ctx, endpoint, strings.NewReader(body)); err != nil {
+			continue
+		}
+		resp := &queryResponse{}
+		if err = json.Unmarshal(data, resp); err != nil {
+			return errors.WithMessage(err, "failed to parse the response of ts-sql")
+		}
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+		for _, result := range resp.Results {
+			if result.Error != "" {
+				return errors.New(result.Error)
+			}
+		}
+		return nil
+	}
+	return errors.WithMessagef(err, "failed to request ts-sql %v", endpoints)
+}
+
+// CreateAdminUser creates a user with all privileges
+func (sc *TSSqlClient) CreateAdminUser(user, password string) error {
+	return sc.Query(fmt.Sprintf(`CREATE USER "%s" WITH PASSWORD '%s' WITH ALL PRIVILEGES`,
+		user, strings.ReplaceAll(password, `'`, `\'`)))
+}
@@ -35,6 +35,8 @@ import (
 const (
 	// EnvNameAuditID is the alternative ID appended to time based audit ID
 	EnvNameAuditID = "GEMIX_AUDIT_ID"
+	// CheckpointDir is the subdirectory of audit dir to save the checkpoints of operations
+	CheckpointDir = "checkpoint"
 )
 
 // CommandArgs returns the original commands from the first line of a file
@@ -144,12 +146,32 @@ func GetAuditList(dir string) ([]Item, error) {
 	return auditList, nil
 }
 
-// OutputAuditLog outputs audit log.
-func OutputAuditLog(dir, fileSuffix string, data []byte) error {
+// ValidateAuditID checks the audit ID is a file name in the audit dir rather
+// than a path which refers to the files elsewhere.
+func ValidateAuditID(auditID string) error {
+	if auditID == "" || auditID == "." || auditID == ".." || filepath.Base(auditID) != auditID ||
+		strings.ContainsAny(auditID, `/\`) {
+		return errors.Errorf("invalid audit id '%s'", auditID)
+	}
+	return nil
+}
+
+// NewAuditID generates a time based audit ID.
+func NewAuditID() string {
 	auditID := base52.Encode(time.Now().UnixNano() + rand.Int63n(1000))
 	if customID := os.Getenv(EnvNameAuditID); customID != "" {
 		auditID = fmt.Sprintf("%s_%s", auditID, customID)
 	}
+	return auditID
+}
+
+// CheckpointPath returns the path of the checkpoint file of the audit log.
+func CheckpointPath(dir, auditID string) string {
+	return filepath.Join(dir, CheckpointDir, auditID)
+}
+
+// OutputAuditLog outputs audit log.
+func OutputAuditLog(dir, auditID, fileSuffix string, data []byte) error {
 	if fileSuffix != "" {
 		auditID = fmt.Sprintf("%s_%s", auditID, fileSuffix)
 	}
@@ -173,6 +195,9 @@ func OutputAuditLog(dir, fileSuffix string, data []byte) error {
 
 // ShowAuditLog show the audit with the specified auditID
 func ShowAuditLog(dir string, auditID string) error {
+	if err := ValidateAuditID(auditID); err != nil {
+		return err
+	}
 	path := filepath.Join(dir, auditID)
 	if utils.IsNotExist(path) {
 		return errors.Errorf("cannot find the audit log '%s'", auditID)
@@ -291,6 +316,9 @@ func DeleteAuditLog(dir string, retainDays int, skipConfirm bool, displayMode st
 		if err := os.Remove(f); err != nil {
 			return err
 		}
+		if err := os.Remove(CheckpointPath(dir, filepath.Base(f))); err != nil && !os.IsNotExist(err) {
+			return err
+		}
 	}
 
 	if displayMode != "json" {
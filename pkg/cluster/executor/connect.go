@@ -26,14 +26,6 @@ import (
 	"golang.org/x/crypto/ssh"
 )
 
-type SSHType int32
-
-const (
-	SSH_UNKNOW SSHType = 0
-	SSH_PW     SSHType = 1
-	SSH_KEY    SSHType = 2
-)
-
 func NewSshByPwd(user, password, host string, port int) (*ssh.Client, error) {
 	var (
 		auth         []ssh.AuthMethod
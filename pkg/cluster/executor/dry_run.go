@@ -0,0 +1,147 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// maxRenderSize is the max size of the transferred files rendered to the
+// local directory, larger files such as the packages are only recorded
+const maxRenderSize = 1 << 20
+
+var dryRun *DryRunRecorder
+
+// EnableDryRun makes all the executors created later record the operations
+// instead of performing them, the transferred files are rendered to dir.
+func EnableDryRun(dir string) *DryRunRecorder {
+	dryRun = &DryRunRecorder{
+		dir:   dir,
+		hosts: make(map[string][]string),
+	}
+	return dryRun
+}
+
+// DryRunEnabled returns the recorder if dry run is enabled, or nil
+func DryRunEnabled() *DryRunRecorder {
+	return dryRun
+}
+
+// DryRunRecorder records the operations on each host in dry run mode
+type DryRunRecorder struct {
+	mu    sync.Mutex
+	dir   string
+	hosts map[string][]string // host -> operations in order
+}
+
+func (r *DryRunRecorder) record(host, format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hosts[host] = append(r.hosts[host], fmt.Sprintf(format, args...))
+}
+
+// RecordAPI records the request to the API of an instance on the host, the
+// requests are not sent in dry run mode as the instances are not operated
+func (r *DryRunRecorder) RecordAPI(host, method, url string) {
+	r.record(host, "[api] %s %s", method, url)
+}
+
+// Dir returns the directory where the transferred files are rendered
+func (r *DryRunRecorder) Dir() string {
+	return r.dir
+}
+
+// Print writes the plan of every host to w, nothing is written if no
+// operation is recorded
+func (r *DryRunRecorder) Print(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.hosts) == 0 {
+		return
+	}
+
+	hosts := make([]string, 0, len(r.hosts))
+	for host := range r.hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	fmt.Fprintf(w, "\nDry run plan, nothing is executed on the hosts:\n")
+	for _, host := range hosts {
+		fmt.Fprintf(w, "\n%s\n", color.CyanString("Host %s:", host))
+		for i, op := range r.hosts[host] {
+			fmt.Fprintf(w, "  %3d. %s\n", i+1, op)
+		}
+	}
+	fmt.Fprintf(w, "\nThe files to transfer are rendered in %s\n", r.dir)
+}
+
+// DryRun implements Executor, it records the commands and the file
+// transfers of a host without performing them.
+type DryRun struct {
+	Config   SSHConfig
+	Sudo     bool
+	recorder *DryRunRecorder
+}
+
+func (r *DryRunRecorder) newExecutor(sudo bool, c SSHConfig) *DryRun {
+	return &DryRun{Config: c, Sudo: sudo, recorder: r}
+}
+
+// Execute implements Executor interface, the command is recorded and the
+// output is always empty.
+func (e *DryRun) Execute(_ context.Context, cmd string, sudo bool, timeout ...time.Duration) ([]byte, []byte, error) {
+	if len(timeout) == 0 {
+		timeout = append(timeout, executeDefaultTimeout)
+	}
+	e.recorder.record(e.Config.Host, "[exec] user=%s sudo=%t timeout=%s: %s",
+		e.Config.User, e.Sudo || sudo, timeout[0], cmd)
+	return nil, nil, nil
+}
+
+// Transfer implements Executor interface, the uploaded file is rendered to
+// the directory of the host in the local dir if it's small enough.
+func (e *DryRun) Transfer(_ context.Context, src, dst string, download bool, limit int, compress bool) error {
+	if download {
+		e.recorder.record(e.Config.Host, "[download] %s -> local:%s (limit=%d, compress=%t)", src, dst, limit, compress)
+		return nil
+	}
+	e.recorder.record(e.Config.Host, "[upload] local:%s -> %s (limit=%d, compress=%t)", src, dst, limit, compress)
+
+	fi, err := os.Stat(src)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if fi.IsDir() || fi.Size() > maxRenderSize {
+		return nil
+	}
+	target := filepath.Join(e.recorder.dir, e.Config.Host, strings.TrimPrefix(dst, "~"))
+	if err = utils.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(utils.Copy(src, target))
+}
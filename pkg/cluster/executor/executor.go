@@ -15,19 +15,51 @@
 package executor
 
 import (
+	"net"
 	"time"
 
 	"github.com/joomcode/errorx"
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	"github.com/pkg/errors"
 )
 
+// SSHType represents the connection type of the executor
+type SSHType string
+
+const (
+	// SSHTypeAuto uses the local executor for loopback hosts and the builtin SSH for others
+	SSHTypeAuto SSHType = ""
+	// SSHTypeBuiltin uses the builtin SSH client
+	SSHTypeBuiltin SSHType = "builtin"
+	// SSHTypeNone executes all commands on the local machine
+	SSHTypeNone SSHType = "none"
+)
+
+// Validate checks if the SSHType is supported
+func (t SSHType) Validate() error {
+	switch t {
+	case SSHTypeAuto, SSHTypeBuiltin, SSHTypeNone:
+		return nil
+	}
+	return errors.Errorf("unsupported ssh type '%s', supported values: %s, %s", t, SSHTypeBuiltin, SSHTypeNone)
+}
+
 var (
 	errNS = errorx.NewNamespace("executor")
 
 	executeDefaultTimeout = time.Minute
 )
 
-// New create a new Executor
-func New(sudo bool, c SSHConfig) (*EasySSHExecutor, error) {
+// New create a new Executor, the local executor is used if the ssh type is none
+// or the host is a loopback address, and the dry run executor is used if enabled
+func New(etype SSHType, sudo bool, c SSHConfig) (ctxt.Executor, error) {
+	if dryRun != nil {
+		return dryRun.newExecutor(sudo, c), nil
+	}
+	if etype == SSHTypeNone || (etype == SSHTypeAuto && isLoopback(c.Host)) {
+		return NewLocal(sudo, c)
+	}
+
 	// set default values
 	if c.Port <= 0 {
 		c.Port = 22
@@ -44,3 +76,12 @@ func New(sudo bool, c SSHConfig) (*EasySSHExecutor, error) {
 	e.initialize(c)
 	return e, nil
 }
+
+// isLoopback checks if the host refers to the local machine
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
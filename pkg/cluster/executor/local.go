@@ -0,0 +1,166 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Local executes the commands on the local machine, so that a topology on
+// the localhost can be deployed without a running sshd.
+type Local struct {
+	Config SSHConfig
+	Locale string // the locale used when executing the command
+	Sudo   bool   // all commands run with this executor will be using sudo
+}
+
+// NewLocal creates a local executor, the commands are executed as
+// the user in the config via sudo if it's not the current user.
+func NewLocal(sudo bool, c SSHConfig) (*Local, error) {
+	if c.User == "" {
+		u, err := user.Current()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		c.User = u.Username
+	}
+	return &Local{
+		Config: c,
+		Locale: "C",
+		Sudo:   sudo,
+	}, nil
+}
+
+// Execute implements Executor interface.
+func (l *Local) Execute(ctx context.Context, cmd string, sudo bool, timeout ...time.Duration) ([]byte, []byte, error) {
+	current, err := user.Current()
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	cmd = l.wrapCommand(cmd, sudo, current)
+
+	if len(timeout) == 0 {
+		timeout = append(timeout, executeDefaultTimeout)
+	}
+	if timeout[0] > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout[0])
+		defer cancel()
+	}
+
+	command := exec.CommandContext(ctx, "/bin/bash", "-c", cmd)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	command.Stdout = stdout
+	command.Stderr = stderr
+	err = command.Run()
+
+	logfn := zap.L().Info
+	if err != nil {
+		logfn = zap.L().Error
+	}
+	logfn("LocalCommand",
+		zap.String("user", l.Config.User),
+		zap.String("cmd", cmd),
+		zap.Error(err),
+		zap.String("stdout", stdout.String()),
+		zap.String("stderr", stderr.String()))
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return stdout.Bytes(), stderr.Bytes(), ErrSSHExecuteTimedout.
+			Wrap(ctx.Err(), "Execute command locally timedout for '%s'", l.Config.User).
+			WithProperty(ErrPropSSHCommand, cmd).
+			WithProperty(ErrPropSSHStdout, stdout).
+			WithProperty(ErrPropSSHStderr, stderr)
+	}
+
+	if err != nil {
+		baseErr := ErrSSHExecuteFailed.
+			Wrap(err, "Failed to execute command locally for '%s'", l.Config.User).
+			WithProperty(ErrPropSSHCommand, cmd).
+			WithProperty(ErrPropSSHStdout, stdout).
+			WithProperty(ErrPropSSHStderr, stderr)
+		if stdout.Len() > 0 || stderr.Len() > 0 {
+			output := strings.TrimSpace(strings.Join([]string{stdout.String(), stderr.String()}, "\n"))
+			baseErr = baseErr.
+				WithProperty(gui.SuggestionFromFormat("Command output on local host:\n%s\n",
+					color.YellowString(output)))
+		}
+		return stdout.Bytes(), stderr.Bytes(), baseErr
+	}
+
+	return stdout.Bytes(), stderr.Bytes(), nil
+}
+
+// wrapCommand wraps the command to run it by the current user
+func (l *Local) wrapCommand(cmd string, sudo bool, current *user.User) string {
+	// try to acquire root permission, or switch to the expected user
+	switch {
+	case l.Sudo || sudo:
+		if current.Uid != "0" {
+			cmd = fmt.Sprintf("/usr/bin/sudo -H bash -c \"%s\"", cmd)
+		}
+	case current.Username != l.Config.User:
+		cmd = fmt.Sprintf("/usr/bin/sudo -H -u %s bash -c \"%s\"", l.Config.User, cmd)
+	}
+
+	// set a basic PATH in case it's empty on login
+	cmd = fmt.Sprintf("PATH=$PATH:/bin:/sbin:/usr/bin:/usr/sbin %s", cmd)
+
+	if l.Locale != "" {
+		cmd = fmt.Sprintf("export LANG=%s; %s", l.Locale, cmd)
+	}
+	return cmd
+}
+
+// Transfer implements Executor interface, the file is copied directly and
+// the bandwidth limit and compression are ignored.
+func (l *Local) Transfer(ctx context.Context, src, dst string, download bool, _ int, _ bool) error {
+	current, err := user.Current()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if download || current.Username == l.Config.User {
+		if err = utils.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return errors.WithStack(err)
+		}
+		if err = utils.Copy(src, dst); err != nil {
+			return errors.WithMessagef(err, "failed to copy %s to %s", src, dst)
+		}
+		return nil
+	}
+
+	// the file must be owned by the expected user
+	cmd := fmt.Sprintf("mkdir -p %[3]s && cp %[1]s %[2]s && chown %[4]s:$(id -g -n %[4]s) %[2]s",
+		src, dst, filepath.Dir(dst), l.Config.User)
+	if _, _, err = l.Execute(ctx, cmd, true); err != nil {
+		return errors.WithMessagef(err, "failed to copy %s to %s@localhost:%s", src, l.Config.User, dst)
+	}
+	return nil
+}
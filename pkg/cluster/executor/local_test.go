@@ -0,0 +1,143 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsLoopback(t *testing.T) {
+	cases := map[string]bool{
+		"localhost":   true,
+		"127.0.0.1":   true,
+		"127.1.2.3":   true,
+		"::1":         true,
+		"0.0.0.0":     false,
+		"192.168.0.1": false,
+		"::":          false,
+		"gemini-1":    false,
+		"":            false,
+	}
+	for host, expected := range cases {
+		assert.Equal(t, expected, isLoopback(host), host)
+	}
+}
+
+func TestNewLocal(t *testing.T) {
+	e, err := New(SSHTypeAuto, false, SSHConfig{Host: "127.0.0.1", User: "gemini"})
+	require.NoError(t, err)
+	assert.IsType(t, &Local{}, e)
+
+	e, err = New(SSHTypeNone, false, SSHConfig{Host: "192.168.0.1", User: "gemini"})
+	require.NoError(t, err)
+	assert.IsType(t, &Local{}, e)
+
+	e, err = New(SSHTypeBuiltin, false, SSHConfig{Host: "127.0.0.1", User: "gemini"})
+	require.NoError(t, err)
+	assert.IsType(t, &EasySSHExecutor{}, e)
+
+	// the current user is used by default
+	current, err := user.Current()
+	require.NoError(t, err)
+	local, err := NewLocal(false, SSHConfig{Host: "127.0.0.1"})
+	require.NoError(t, err)
+	assert.Equal(t, current.Username, local.Config.User)
+}
+
+func TestLocalWrapCommand(t *testing.T) {
+	root := &user.User{Uid: "0", Username: "root"}
+	gemini := &user.User{Uid: "1000", Username: "gemini"}
+	const path = "PATH=$PATH:/bin:/sbin:/usr/bin:/usr/sbin "
+
+	cases := []struct {
+		local    *Local
+		sudo     bool
+		current  *user.User
+		expected string
+	}{
+		// the command runs directly by the expected user
+		{&Local{Config: SSHConfig{User: "gemini"}}, false, gemini, path + "ls"},
+		{&Local{Config: SSHConfig{User: "gemini"}, Locale: "C"}, false, gemini, "export LANG=C; " + path + "ls"},
+		// switch to the expected user
+		{&Local{Config: SSHConfig{User: "gemini"}}, false, root, path + `/usr/bin/sudo -H -u gemini bash -c "ls"`},
+		// acquire root permission
+		{&Local{Config: SSHConfig{User: "gemini"}}, true, gemini, path + `/usr/bin/sudo -H bash -c "ls"`},
+		{&Local{Config: SSHConfig{User: "gemini"}, Sudo: true}, false, gemini, path + `/usr/bin/sudo -H bash -c "ls"`},
+		{&Local{Config: SSHConfig{User: "gemini"}, Sudo: true}, false, root, path + "ls"},
+		{&Local{Config: SSHConfig{User: "root"}}, true, root, path + "ls"},
+	}
+	for i, c := range cases {
+		assert.Equal(t, c.expected, c.local.wrapCommand("ls", c.sudo, c.current), "case %d", i)
+	}
+}
+
+func TestLocalExecute(t *testing.T) {
+	local, err := NewLocal(false, SSHConfig{Host: "127.0.0.1"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	stdout, stderr, err := local.Execute(ctx, "echo $LANG; echo gemix >&2", false)
+	require.NoError(t, err)
+	assert.Equal(t, "C\n", string(stdout))
+	assert.Equal(t, "gemix\n", string(stderr))
+
+	stdout, stderr, err = local.Execute(ctx, "echo gemix; exit 3", false)
+	require.Error(t, err)
+	assert.True(t, errorx.IsOfType(err, ErrSSHExecuteFailed))
+	assert.Equal(t, "gemix\n", string(stdout))
+	assert.Empty(t, stderr)
+
+	start := time.Now()
+	_, _, err = local.Execute(ctx, "sleep 10", false, time.Millisecond*100)
+	require.Error(t, err)
+	assert.True(t, errorx.IsOfType(err, ErrSSHExecuteTimedout))
+	assert.Less(t, time.Since(start), time.Second*5)
+}
+
+func TestLocalTransfer(t *testing.T) {
+	local, err := NewLocal(false, SSHConfig{Host: "127.0.0.1"})
+	require.NoError(t, err)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	src := filepath.Join(dir, "ts-meta.toml")
+	require.NoError(t, os.WriteFile(src, []byte("[meta]\n"), 0644))
+
+	// the parent directories are created
+	dst := filepath.Join(dir, "deploy", "conf", "ts-meta.toml")
+	require.NoError(t, local.Transfer(ctx, src, dst, false, 0, true))
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "[meta]\n", string(data))
+
+	dst = filepath.Join(dir, "download", "ts-meta.toml")
+	require.NoError(t, local.Transfer(ctx, src, dst, true, 1, false))
+	data, err = os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "[meta]\n", string(data))
+
+	err = local.Transfer(ctx, filepath.Join(dir, "missing"), dst, false, 0, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to copy "+filepath.Join(dir, "missing"))
+}
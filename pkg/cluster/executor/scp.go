@@ -0,0 +1,328 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// ScpUpload uploads a file to remote with SCP, the remote host acts as the sink of
+// the SCP protocol (`scp -t`). The bandwidth is limited to limit KB/s if limit > 0,
+// and the content is gzipped on the wire and extracted on remote if compress is set.
+func ScpUpload(session *ssh.Session, client *ssh.Client, src, dst string, limit int, compress bool) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer srcFile.Close()
+
+	srcStat, err := srcFile.Stat()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	w := newLimitWriter(stdin, limit)
+
+	if compress {
+		var stderr bytes.Buffer
+		session.Stderr = &stderr
+
+		copyErrC := make(chan error, 1)
+		go func() {
+			defer stdin.Close()
+			gw := gzip.NewWriter(w)
+			if _, err := io.Copy(gw, srcFile); err != nil {
+				copyErrC <- errors.WithStack(err)
+				return
+			}
+			copyErrC <- errors.WithStack(gw.Close())
+		}()
+
+		if err := session.Run(fmt.Sprintf("gzip -dc > %s", dst)); err != nil {
+			return errors.WithMessagef(err, "failed to upload %s: %s", dst, strings.TrimSpace(stderr.String()))
+		}
+		return <-copyErrC
+	}
+
+	r, err := session.StdoutPipe()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	bufr := bufio.NewReader(r)
+
+	copyF := func() error {
+		// wait for the sink to be ready
+		if err := readScpAck(bufr); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, "C0644", srcStat.Size(), filepath.Base(dst)); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := readScpAck(bufr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, srcFile); err != nil {
+			return errors.WithMessagef(err, "failed to upload %s", src)
+		}
+		if _, err := w.Write([]byte{0}); err != nil {
+			return errors.WithStack(err)
+		}
+		return readScpAck(bufr)
+	}
+
+	copyErrC := make(chan error, 1)
+	go func() {
+		defer stdin.Close()
+		copyErrC <- copyF()
+	}()
+
+	if err := session.Start(fmt.Sprintf("scp -t %s", dst)); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := <-copyErrC; err != nil {
+		return err
+	}
+	return errors.WithStack(session.Wait())
+}
+
+// readScpAck reads the response of the remote scp, which is \x00 on success, or
+// \x01 (warning) and \x02 (fatal error) followed by the message
+func readScpAck(r *bufio.Reader) error {
+	code, err := r.ReadByte()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if code == 0 {
+		return nil
+	}
+	msg, _ := r.ReadString('\n')
+	return errors.Errorf("remote scp error: %s", strings.TrimSpace(msg))
+}
+
+// ScpDownload downloads a file from remote with SCP, the remote host acts as the
+// source of the SCP protocol (`scp -f`) and the file is written to dst. The bandwidth
+// is limited to limit KB/s if limit > 0, and the content is gzipped on remote and
+// extracted on the fly if compress is set.
+func ScpDownload(session *ssh.Session, client *ssh.Client, src, dst string, limit int, compress bool) error {
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	r := newLimitReader(stdout, limit)
+
+	if compress {
+		return gzipDownload(session, r, src, dst)
+	}
+	bufr := bufio.NewReader(r)
+
+	w, err := session.StdinPipe()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	copyF := func() error {
+		// parse the SCP command, e.g. "C0644 1024 file.tar.gz"
+		line, err := bufr.ReadString('\n')
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if len(line) == 0 || line[0] != 'C' {
+			// the remote scp reports errors with a leading \x01 or \x02
+			return errors.Errorf("failed to download %s: %s", src, strings.TrimSpace(strings.TrimLeft(line, "\x01\x02")))
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return errors.Errorf("incorrect scp command '%s'", strings.TrimSpace(line))
+		}
+		mode, err := strconv.ParseUint(fields[0][1:], 8, 32)
+		if err != nil {
+			return errors.WithMessagef(err, "incorrect file mode in scp command '%s'", strings.TrimSpace(line))
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return errors.WithMessagef(err, "incorrect file size in scp command '%s'", strings.TrimSpace(line))
+		}
+
+		targetFile, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE|os.O_TRUNC, fs.FileMode(mode))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer targetFile.Close()
+
+		// ready to receive the content
+		if _, err := w.Write([]byte{0}); err != nil {
+			return errors.WithStack(err)
+		}
+		if _, err := io.CopyN(targetFile, bufr, size); err != nil {
+			return errors.WithMessagef(err, "failed to download %s", src)
+		}
+		// the content is followed by a \x00 from the source
+		if _, err := bufr.ReadByte(); err != nil {
+			return errors.WithStack(err)
+		}
+		// finish the transfer
+		if _, err := w.Write([]byte{0}); err != nil {
+			return errors.WithStack(err)
+		}
+		return nil
+	}
+
+	copyErrC := make(chan error, 1)
+	go func() {
+		defer w.Close()
+		// start the transfer
+		if _, err := w.Write([]byte{0}); err != nil {
+			copyErrC <- errors.WithStack(err)
+			return
+		}
+		copyErrC <- copyF()
+	}()
+
+	if err := session.Start(fmt.Sprintf("scp -f %s", src)); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := <-copyErrC; err != nil {
+		return err
+	}
+	return errors.WithStack(session.Wait())
+}
+
+// gzipDownload reads the content of src gzipped on remote and extracts it to dst
+func gzipDownload(session *ssh.Session, r io.Reader, src, dst string) error {
+	var stderr bytes.Buffer
+	session.Stderr = &stderr
+
+	if err := session.Start(fmt.Sprintf("gzip -c %s", src)); err != nil {
+		return errors.WithStack(err)
+	}
+
+	copyF := func() error {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer gr.Close()
+
+		targetFile, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer targetFile.Close()
+
+		_, err = io.Copy(targetFile, gr)
+		return errors.WithStack(err)
+	}
+
+	copyErr := copyF()
+	// drain the output so the remote command can exit
+	_, _ = io.Copy(io.Discard, r)
+	if err := session.Wait(); err != nil {
+		return errors.WithMessagef(err, "failed to download %s: %s", src, strings.TrimSpace(stderr.String()))
+	}
+	if copyErr != nil {
+		return errors.WithMessagef(copyErr, "failed to download %s", src)
+	}
+	return nil
+}
+
+// limiter throttles the transfer to the given bandwidth
+type limiter struct {
+	limit int64 // bytes per second
+	start time.Time
+	total int64
+}
+
+// wait blocks until n more bytes are allowed to be transferred
+func (l *limiter) wait(n int) {
+	if l.start.IsZero() {
+		l.start = time.Now()
+	}
+	l.total += int64(n)
+	expect := time.Duration(float64(l.total) / float64(l.limit) * float64(time.Second))
+	if d := expect - time.Since(l.start); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// chunk returns the max size of a single read or write
+func (l *limiter) chunk(n int) int {
+	// allow at most 1/10 of the bandwidth at once to smooth the transfer
+	if c := int(l.limit / 10); c > 0 && n > c {
+		return c
+	}
+	return n
+}
+
+type limitReader struct {
+	r io.Reader
+	l *limiter
+}
+
+// newLimitReader returns a reader limited to limit KB/s, or r itself if limit <= 0
+func newLimitReader(r io.Reader, limit int) io.Reader {
+	if limit <= 0 {
+		return r
+	}
+	return &limitReader{r: r, l: &limiter{limit: int64(limit) * 1024}}
+}
+
+func (lr *limitReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p[:lr.l.chunk(len(p))])
+	lr.l.wait(n)
+	return n, err
+}
+
+type limitWriter struct {
+	w io.Writer
+	l *limiter
+}
+
+// newLimitWriter returns a writer limited to limit KB/s, or w itself if limit <= 0
+func newLimitWriter(w io.Writer, limit int) io.Writer {
+	if limit <= 0 {
+		return w
+	}
+	return &limitWriter{w: w, l: &limiter{limit: int64(limit) * 1024}}
+}
+
+func (lw *limitWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		n, err := lw.w.Write(p[written : written+lw.l.chunk(len(p)-written)])
+		written += n
+		lw.l.wait(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
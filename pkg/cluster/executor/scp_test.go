@@ -0,0 +1,403 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// execHandler serves an exec request of the fake ssh server, it returns the exit status
+type execHandler func(cmd string, stdin io.Reader, stdout, stderr io.Writer) uint32
+
+// fakeSSHServer is an in-process ssh server which runs the handler for each exec request
+type fakeSSHServer struct {
+	listener net.Listener
+	handler  execHandler
+
+	mu   sync.Mutex
+	cmds []string
+}
+
+func newFakeSSHServer(t *testing.T, handler execHandler) *fakeSSHServer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeSSHServer{listener: listener, handler: handler}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, config)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSSHServer) serve(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer channel.Close()
+			for req := range requests {
+				if req.Type != "exec" {
+					_ = req.Reply(false, nil)
+					continue
+				}
+				var payload struct{ Command string }
+				if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+					_ = req.Reply(false, nil)
+					continue
+				}
+				_ = req.Reply(true, nil)
+
+				s.mu.Lock()
+				s.cmds = append(s.cmds, payload.Command)
+				s.mu.Unlock()
+
+				status := s.handler(payload.Command, channel, channel, channel.Stderr())
+				_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+				return
+			}
+		}()
+	}
+}
+
+// session dials the server and opens a new session
+func (s *fakeSSHServer) session(t *testing.T) (*ssh.Session, *ssh.Client) {
+	client, err := ssh.Dial("tcp", s.listener.Addr().String(), &ssh.ClientConfig{
+		User:            "gemini",
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // #nosec G106
+		Timeout:         time.Second * 5,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	session, err := client.NewSession()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+	return session, client
+}
+
+func (s *fakeSSHServer) commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.cmds...)
+}
+
+// scpSource serves `scp -f` with the raw header and content, the header is sent
+// after the sink is ready, and the content only if the sink accepts the header
+func scpSource(header string, content []byte) execHandler {
+	return func(cmd string, stdin io.Reader, stdout, stderr io.Writer) uint32 {
+		ack := make([]byte, 1)
+		if _, err := io.ReadFull(stdin, ack); err != nil {
+			return 1
+		}
+		if _, err := io.WriteString(stdout, header); err != nil || header == "" || header[0] != 'C' {
+			return 1
+		}
+		if _, err := io.ReadFull(stdin, ack); err != nil {
+			return 1
+		}
+		_, _ = stdout.Write(append(append([]byte(nil), content...), 0))
+		if _, err := io.ReadFull(stdin, ack); err != nil {
+			return 1
+		}
+		return 0
+	}
+}
+
+// scpSink serves `scp -t`, the received header and content are saved, and reply
+// is sent instead of the ack of the header if it's not empty
+func scpSink(header *string, content *bytes.Buffer, reply string) execHandler {
+	return func(cmd string, stdin io.Reader, stdout, stderr io.Writer) uint32 {
+		r := bufio.NewReader(stdin)
+		_, _ = stdout.Write([]byte{0})
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return 1
+		}
+		*header = line
+		if reply != "" {
+			_, _ = io.WriteString(stdout, reply)
+			return 1
+		}
+		_, _ = stdout.Write([]byte{0})
+
+		var size int64
+		if _, err := fmt.Sscanf(line, "C0644 %d", &size); err != nil {
+			return 1
+		}
+		if _, err := io.CopyN(content, r, size); err != nil {
+			return 1
+		}
+		if b, err := r.ReadByte(); err != nil || b != 0 {
+			return 1
+		}
+		_, _ = stdout.Write([]byte{0})
+		return 0
+	}
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func TestScpDownload(t *testing.T) {
+	content := []byte("hello openGemini\n")
+	server := newFakeSSHServer(t, scpSource(fmt.Sprintf("C0640 %d gemix.log\n", len(content)), content))
+	session, client := server.session(t)
+
+	dst := filepath.Join(t.TempDir(), "gemix.log")
+	require.NoError(t, ScpDownload(session, client, "/data/gemix.log", dst, 0, false))
+	assert.Equal(t, []string{"scp -f /data/gemix.log"}, server.commands())
+
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	fi, err := os.Stat(dst)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), fi.Mode().Perm())
+}
+
+func TestScpDownloadError(t *testing.T) {
+	cases := []struct {
+		header string
+		err    string
+	}{
+		{"\x01scp: /data/gemix.log: No such file or directory\n", "failed to download /data/gemix.log: scp: /data/gemix.log: No such file or directory"},
+		{"\x02scp: /data/gemix.log: Permission denied\n", "failed to download /data/gemix.log: scp: /data/gemix.log: Permission denied"},
+		{"C0644 5\n", "incorrect scp command 'C0644 5'"},
+		{"C06x4 5 gemix.log\n", "incorrect file mode in scp command 'C06x4 5 gemix.log'"},
+		{"C0644 five gemix.log\n", "incorrect file size in scp command 'C0644 five gemix.log'"},
+	}
+	for _, c := range cases {
+		server := newFakeSSHServer(t, scpSource(c.header, nil))
+		session, client := server.session(t)
+
+		dst := filepath.Join(t.TempDir(), "gemix.log")
+		err := ScpDownload(session, client, "/data/gemix.log", dst, 0, false)
+		require.Error(t, err, c.header)
+		assert.Contains(t, err.Error(), c.err)
+		assert.NoFileExists(t, dst)
+	}
+}
+
+func TestScpDownloadCompress(t *testing.T) {
+	content := bytes.Repeat([]byte("openGemini "), 1024)
+	server := newFakeSSHServer(t, func(cmd string, stdin io.Reader, stdout, stderr io.Writer) uint32 {
+		_, _ = stdout.Write(gzipBytes(t, content))
+		return 0
+	})
+	session, client := server.session(t)
+
+	dst := filepath.Join(t.TempDir(), "gemix.log")
+	require.NoError(t, ScpDownload(session, client, "/data/gemix.log", dst, 0, true))
+	assert.Equal(t, []string{"gzip -c /data/gemix.log"}, server.commands())
+
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+}
+
+func TestScpDownloadCompressError(t *testing.T) {
+	server := newFakeSSHServer(t, func(cmd string, stdin io.Reader, stdout, stderr io.Writer) uint32 {
+		_, _ = io.WriteString(stderr, "gzip: /data/gemix.log: No such file or directory\n")
+		return 1
+	})
+	session, client := server.session(t)
+
+	err := ScpDownload(session, client, "/data/gemix.log", filepath.Join(t.TempDir(), "gemix.log"), 0, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to download /data/gemix.log: gzip: /data/gemix.log: No such file or directory")
+}
+
+func TestScpUpload(t *testing.T) {
+	content := []byte("hello openGemini\n")
+	src := filepath.Join(t.TempDir(), "gemix.toml")
+	require.NoError(t, os.WriteFile(src, content, 0644))
+
+	var header string
+	var received bytes.Buffer
+	server := newFakeSSHServer(t, scpSink(&header, &received, ""))
+	session, client := server.session(t)
+
+	require.NoError(t, ScpUpload(session, client, src, "/data/conf/ts-meta.toml", 0, false))
+	assert.Equal(t, []string{"scp -t /data/conf/ts-meta.toml"}, server.commands())
+	assert.Equal(t, fmt.Sprintf("C0644 %d ts-meta.toml\n", len(content)), header)
+	assert.Equal(t, content, received.Bytes())
+}
+
+func TestScpUploadError(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "gemix.toml")
+	require.NoError(t, os.WriteFile(src, []byte("hello"), 0644))
+
+	for _, reply := range []string{"\x01scp: /data/conf: No such file or directory\n", "\x02scp: /data/conf: Permission denied\n"} {
+		var header string
+		var received bytes.Buffer
+		server := newFakeSSHServer(t, scpSink(&header, &received, reply))
+		session, client := server.session(t)
+
+		err := ScpUpload(session, client, src, "/data/conf/ts-meta.toml", 0, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "remote scp error: "+strings.TrimSpace(reply[1:]))
+		assert.Zero(t, received.Len())
+	}
+}
+
+func TestScpUploadCompress(t *testing.T) {
+	content := bytes.Repeat([]byte("openGemini "), 1024)
+	src := filepath.Join(t.TempDir(), "gemix.tar")
+	require.NoError(t, os.WriteFile(src, content, 0644))
+
+	var received []byte
+	server := newFakeSSHServer(t, func(cmd string, stdin io.Reader, stdout, stderr io.Writer) uint32 {
+		gr, err := gzip.NewReader(stdin)
+		if err != nil {
+			return 1
+		}
+		if received, err = io.ReadAll(gr); err != nil {
+			return 1
+		}
+		return 0
+	})
+	session, client := server.session(t)
+
+	require.NoError(t, ScpUpload(session, client, src, "/data/gemix.tar", 0, true))
+	assert.Equal(t, []string{"gzip -dc > /data/gemix.tar"}, server.commands())
+	assert.Equal(t, content, received)
+}
+
+func TestScpUploadCompressError(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "gemix.tar")
+	require.NoError(t, os.WriteFile(src, []byte("hello"), 0644))
+
+	server := newFakeSSHServer(t, func(cmd string, stdin io.Reader, stdout, stderr io.Writer) uint32 {
+		_, _ = io.Copy(io.Discard, stdin)
+		_, _ = io.WriteString(stderr, "sh: /data/gemix.tar: Permission denied\n")
+		return 1
+	})
+	session, client := server.session(t)
+
+	err := ScpUpload(session, client, src, "/data/gemix.tar", 0, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to upload /data/gemix.tar: sh: /data/gemix.tar: Permission denied")
+}
+
+// chunkWriter records the size of each write
+type chunkWriter struct {
+	bytes.Buffer
+	chunks []int
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.chunks = append(w.chunks, len(p))
+	return w.Buffer.Write(p)
+}
+
+func TestLimitWriter(t *testing.T) {
+	w := &chunkWriter{}
+	assert.Same(t, w, newLimitWriter(w, 0))
+
+	// 1 KB/s allows at most 102 bytes at once
+	data := bytes.Repeat([]byte{'x'}, 300)
+	lw := newLimitWriter(w, 1)
+	start := time.Now()
+	n, err := lw.Write(data)
+	require.NoError(t, err)
+	assert.Equal(t, 300, n)
+	assert.Equal(t, data, w.Bytes())
+	assert.Equal(t, []int{102, 102, 96}, w.chunks)
+	// 300 bytes take about 300/1024 seconds
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*250)
+}
+
+func TestLimitReader(t *testing.T) {
+	src := bytes.NewReader(nil)
+	assert.Same(t, src, newLimitReader(src, -1))
+
+	data := bytes.Repeat([]byte{'x'}, 300)
+	lr := newLimitReader(bytes.NewReader(data), 1)
+	start := time.Now()
+	buf := make([]byte, 1024)
+	var chunks []int
+	var read []byte
+	for {
+		n, err := lr.Read(buf)
+		if n > 0 {
+			chunks = append(chunks, n)
+			read = append(read, buf[:n]...)
+		}
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+	assert.Equal(t, data, read)
+	assert.Equal(t, []int{102, 102, 96}, chunks)
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*250)
+}
+
+func TestLimiter(t *testing.T) {
+	// the chunk is not limited if the bandwidth is less than 10 bytes/s
+	assert.Equal(t, 4096, (&limiter{limit: 9}).chunk(4096))
+	assert.Equal(t, 100, (&limiter{limit: 10240}).chunk(100))
+	assert.Equal(t, 1024, (&limiter{limit: 10240}).chunk(4096))
+
+	// the first bytes are allowed at once when the bandwidth is large enough
+	l := &limiter{limit: 1 << 30}
+	start := time.Now()
+	l.wait(1024)
+	assert.Less(t, time.Since(start), time.Millisecond*100)
+	assert.Equal(t, int64(1024), l.total)
+}
@@ -188,29 +188,31 @@ func (e *EasySSHExecutor) Execute(ctx context.Context, cmd string, sudo bool, ti
 // Transfer copies files via SCP
 // This function depends on `scp` (a tool from OpenSSH or other SSH implementation)
 // This function is based on easyssh.MakeConfig.Scp() but with support of copying
-// file from remote to local.
+// file from remote to local, limiting the bandwidth to limit KB/s and compressing
+// the content on the wire with gzip.
 func (e *EasySSHExecutor) Transfer(ctx context.Context, src, dst string, download bool, limit int, compress bool) error {
+	session, client, err := e.Config.Connect()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer client.Close()
+	defer session.Close()
+
 	if !download {
-		err := e.Config.Scp(src, dst)
-		if err != nil {
+		if err := ScpUpload(session, client, src, dst, limit, compress); err != nil {
 			return errors.WithMessagef(err, "failed to scp %s to %s@%s:%s", src, e.Config.User, e.Config.Server, dst)
 		}
 		return nil
 	}
 
 	// download file from remote
-	session, client, err := e.Config.Connect()
+	err = os.MkdirAll(filepath.Dir(dst), 0750)
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	defer client.Close()
-	defer session.Close()
-
-	err = os.MkdirAll(filepath.Dir(dst), 0750)
-	if err != nil {
-		return nil
+	if err := ScpDownload(session, client, src, dst, limit, compress); err != nil {
+		return errors.WithMessagef(err, "failed to scp %s@%s:%s to %s", e.Config.User, e.Config.Server, src, dst)
 	}
-	//return ScpDownload(session, client, src, dst, limit, compress)
 	return nil
 }
 
@@ -0,0 +1,468 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	operator "github.com/openGemini/gemix/pkg/cluster/operation"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/cluster/task"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/set"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// BackupManifestName is the name of the manifest file in the backup dir
+const BackupManifestName = "manifest.json"
+
+// backupRoles are the components whose data dirs are backed up
+var backupRoles = []string{spec.ComponentTSMeta, spec.ComponentTSStore}
+
+// stoppedRoles are the components stopped during backup and restore
+var stoppedRoles = []string{spec.ComponentTSMeta, spec.ComponentTSStore, spec.ComponentTSSql}
+
+// BackupManifest describes the files of a backup
+type BackupManifest struct {
+	ClusterName string        `json:"cluster_name"`
+	Version     string        `json:"version"`
+	BackupID    string        `json:"backup_id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	OnHost      bool          `json:"on_host"` // the files are kept on the hosts of the instances
+	Files       []*BackupFile `json:"files"`
+}
+
+// BackupFile is the archive of a data dir of an instance
+type BackupFile struct {
+	ID      string `json:"id"`
+	Role    string `json:"role"`
+	Host    string `json:"host"` // the manage host of the instance
+	DataDir string `json:"data_dir"`
+	Path    string `json:"path"` // the name in the backup dir, or the absolute path on the host if on_host is set
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256"`
+}
+
+// Backup archives the data dirs of ts-meta and ts-store with the instances stopped, the archives are
+// pulled to the dir on the control machine, or kept in the dir on each host if onHost is set.
+// openGemini provides no online backup api for the data dirs yet, so the cluster is stopped during
+// the backup and started again afterwards, or when the backup fails.
+func (m *Manager) Backup(name, dir string, onHost bool, gOpt operator.Options, skipConfirm bool) error {
+	if gOpt.DryRun {
+		return errors.New("backup does not support --dry-run, the checksums of the backup files are read from the hosts")
+	}
+	// check locked
+	if err := m.specManager.ScaleOutLockedErr(name); err != nil {
+		return err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+
+	tlsCfg, err := topo.TLSConfig(m.specManager.Path(name, spec.TLSCertKeyDir))
+	if err != nil {
+		return err
+	}
+
+	if onHost && !filepath.IsAbs(dir) {
+		return errors.Errorf("the backup dir on the hosts must be an absolute path: %s", dir)
+	}
+	if dir, err = filepath.Abs(dir); err != nil {
+		return errors.WithStack(err)
+	}
+
+	manifest := &BackupManifest{
+		ClusterName: name,
+		Version:     base.Version,
+		BackupID:    time.Now().Format("20060102150405"),
+		CreatedAt:   time.Now(),
+		OnHost:      onHost,
+	}
+	backupDir := filepath.Join(dir, fmt.Sprintf("%s-%s", name, manifest.BackupID))
+	if !skipConfirm {
+		if err := gui.PromptForConfirmOrAbortError(
+			"This operation will stop the cluster %s during the backup and start it again afterwards.\nThe backup will be saved to %s%s.\nDo you want to continue? [y/N]:",
+			color.HiYellowString(name),
+			color.HiYellowString(backupDir),
+			map[bool]string{true: " on each host", false: ""}[onHost]); err != nil {
+			return err
+		}
+	}
+	if err := utils.MkdirAll(backupDir, 0755); err != nil {
+		return errors.WithStack(err)
+	}
+
+	var mu sync.Mutex
+	var backupTasks []*task.StepDisplay
+	for _, comp := range operator.FilterComponent(topo.ComponentsByStartOrder(), set.NewStringSet(backupRoles...)) {
+		for _, inst := range comp.Instances() {
+			if inst.DataDir() == "" {
+				continue
+			}
+			deployDir := spec.Abs(base.User, inst.DeployDir())
+			tb := task.NewBuilder(m.logger)
+			if onHost {
+				tb.Mkdir(base.User, inst.GetManageHost(), backupDir)
+			}
+			for idx, dataDir := range strings.Split(inst.DataDir(), ",") {
+				file := &BackupFile{
+					ID:      inst.ID(),
+					Role:    inst.ComponentName(),
+					Host:    inst.GetManageHost(),
+					DataDir: spec.Abs(base.User, dataDir),
+					Path:    backupFileName(inst, idx),
+				}
+				if onHost {
+					file.Path = path.Join(backupDir, file.Path)
+				}
+				tb.Func("BackupDataDir", func(ctx context.Context) error {
+					if err := backupDataDir(ctx, file, deployDir, backupDir, onHost); err != nil {
+						return err
+					}
+					mu.Lock()
+					defer mu.Unlock()
+					manifest.Files = append(manifest.Files, file)
+					return nil
+				})
+			}
+			backupTasks = append(backupTasks,
+				tb.BuildAsStep(fmt.Sprintf("  - Backup %s -> %s", inst.ComponentName(), inst.ID())))
+		}
+	}
+
+	stopOpt := gOpt
+	stopOpt.Roles = stoppedRoles
+	stopOpt.Nodes = nil
+
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return err
+	}
+	t := b.
+		FuncWithRollback("StopCluster", func(ctx context.Context) error {
+			return operator.Stop(ctx, topo, stopOpt)
+		}, func(ctx context.Context) error {
+			return operator.Start(ctx, topo, stopOpt, tlsCfg)
+		}).
+		ParallelStep("+ Backup data of instances", false, backupTasks...).
+		Func("StartCluster", func(ctx context.Context) error {
+			return operator.Start(ctx, topo, stopOpt, tlsCfg)
+		}).
+		Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := t.Execute(ctx); err != nil {
+		// the backup is given up and the stopped instances are started again
+		m.logger.Warnf("Failed to back up cluster `%s`, starting the stopped instances again", name)
+		if rbErr := t.Rollback(ctx); rbErr != nil {
+			hint := color.New(color.FgHiBlue).Sprintf("gemix cluster start %s", name)
+			m.logger.Errorf("Failed to start the cluster: %s, you can start it with command: `%s`", rbErr, hint)
+		}
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return err
+		}
+		return errors.WithStack(err)
+	}
+
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Path < manifest.Files[j].Path
+	})
+	manifestPath := filepath.Join(backupDir, BackupManifestName)
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := utils.WriteFile(manifestPath, data, 0644); err != nil {
+		return errors.WithStack(err)
+	}
+
+	hint := color.New(color.FgHiBlue).Sprintf("gemix cluster restore %s --from %s", name, backupDir)
+	m.logger.Infof("Backed up cluster `%s` successfully, the manifest is saved to %s", name, manifestPath)
+	m.logger.Infof("You can restore the cluster with command: `%s`", hint)
+	return nil
+}
+
+// backupDataDir archives the data dir on the host, and pulls the archive to the control machine if needed
+func backupDataDir(ctx context.Context, file *BackupFile, deployDir, backupDir string, onHost bool) error {
+	e, ok := ctxt.GetInner(ctx).GetExecutor(file.Host)
+	if !ok {
+		return task.ErrNoExecutor
+	}
+
+	// the archive is created in the deploy dir before pulled to the control machine
+	archive := file.Path
+	if !onHost {
+		archive = path.Join(deployDir, file.Path)
+	}
+	cmd := fmt.Sprintf("tar -czf %s -C %s .", archive, file.DataDir)
+	if _, stderr, err := e.Execute(ctx, cmd, false); err != nil {
+		return errors.WithMessagef(err, "failed to archive %s on %s: %s", file.DataDir, file.Host, strings.TrimSpace(string(stderr)))
+	}
+
+	if onHost {
+		stdout, stderr, err := e.Execute(ctx, fmt.Sprintf("stat -c %%s %s && sha256sum %s", archive, archive), false)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to check %s on %s: %s", archive, file.Host, strings.TrimSpace(string(stderr)))
+		}
+		fields := strings.Fields(string(stdout))
+		if len(fields) < 2 {
+			return errors.Errorf("unexpected output of checking %s on %s: %s", archive, file.Host, stdout)
+		}
+		if file.Size, err = strconv.ParseInt(fields[0], 10, 64); err != nil {
+			return errors.WithStack(err)
+		}
+		file.SHA256 = fields[1]
+		return nil
+	}
+
+	defer func() {
+		_, _, _ = e.Execute(ctx, fmt.Sprintf("rm -f %s", archive), false)
+	}()
+	localPath := filepath.Join(backupDir, file.Path)
+	if err := e.Transfer(ctx, archive, localPath, true, 0, false); err != nil {
+		return err
+	}
+	return fillFileChecksum(file, localPath)
+}
+
+// fillFileChecksum sets the size and checksum of the local backup file
+func fillFileChecksum(file *BackupFile, localPath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	file.Size = fi.Size()
+	file.SHA256, err = utils.SHA256(f)
+	return err
+}
+
+// backupFileName returns the name of the archive of the data dir of the instance
+func backupFileName(inst spec.Instance, idx int) string {
+	name := fmt.Sprintf("%s-%s-%d", inst.ComponentName(), inst.GetManageHost(), inst.GetPort())
+	if idx > 0 {
+		name = fmt.Sprintf("%s-%d", name, idx)
+	}
+	return name + ".tar.gz"
+}
+
+// Restore puts the data dirs in the backup back to the instances with the cluster stopped
+func (m *Manager) Restore(name, dir string, gOpt operator.Options, skipConfirm bool) error {
+	// check locked
+	if err := m.specManager.ScaleOutLockedErr(name); err != nil {
+		return err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+
+	tlsCfg, err := topo.TLSConfig(m.specManager.Path(name, spec.TLSCertKeyDir))
+	if err != nil {
+		return err
+	}
+
+	manifest, err := readBackupManifest(dir)
+	if err != nil {
+		return err
+	}
+	if manifest.ClusterName != name {
+		return errors.Errorf("the backup is taken from cluster `%s`, not `%s`", manifest.ClusterName, name)
+	}
+	if manifest.Version != base.Version {
+		m.logger.Warnf("The backup is taken from version %s, but the version of the cluster is %s", manifest.Version, base.Version)
+	}
+
+	insts := make(map[string]spec.Instance)
+	topo.IterInstance(func(inst spec.Instance) {
+		insts[inst.ID()] = inst
+	})
+	// the data dirs to be replaced are the ones in the topology, the manifest may be edited
+	dataDirs := make(map[*BackupFile]string)
+	for _, file := range manifest.Files {
+		inst, ok := insts[file.ID]
+		if !ok || inst.ComponentName() != file.Role {
+			return errors.Errorf("the %s instance %s in the backup is not found in the cluster", file.Role, file.ID)
+		}
+		if file.Host != inst.GetManageHost() {
+			return errors.Errorf("the host %s of %s in the backup does not match the cluster", file.Host, file.ID)
+		}
+		for _, dataDir := range strings.Split(inst.DataDir(), ",") {
+			if dataDir != "" && spec.Abs(base.User, dataDir) == file.DataDir {
+				dataDirs[file] = spec.Abs(base.User, dataDir)
+			}
+		}
+		if dataDirs[file] == "" {
+			return errors.Errorf("the data dir %s of %s in the backup does not match the cluster", file.DataDir, file.ID)
+		}
+		if manifest.OnHost {
+			continue
+		}
+		// verify the local files before anything is changed
+		if err := verifyBackupFile(dir, file); err != nil {
+			return err
+		}
+	}
+
+	if !skipConfirm {
+		if err := gui.PromptForConfirmOrAbortError(
+			"This operation will stop the cluster %s and %s with the backup %s taken at %s.\nDo you want to continue? [y/N]:",
+			color.HiYellowString(name),
+			color.HiRedString("replace the data of the instances"),
+			color.HiYellowString(manifest.BackupID),
+			manifest.CreatedAt.Format(time.RFC3339)); err != nil {
+			return err
+		}
+	}
+
+	var restoreTasks []*task.StepDisplay
+	for _, file := range manifest.Files {
+		file := file
+		deployDir := spec.Abs(base.User, insts[file.ID].DeployDir())
+		dataDir := dataDirs[file]
+		tb := task.NewBuilder(m.logger).
+			Func("RestoreDataDir", func(ctx context.Context) error {
+				return restoreDataDir(ctx, file, dataDir, deployDir, dir, manifest.OnHost)
+			})
+		restoreTasks = append(restoreTasks,
+			tb.BuildAsStep(fmt.Sprintf("  - Restore %s -> %s:%s", file.Role, file.ID, dataDir)))
+	}
+
+	stopOpt := gOpt
+	stopOpt.Roles = stoppedRoles
+	stopOpt.Nodes = nil
+
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return err
+	}
+	t := b.
+		Func("StopCluster", func(ctx context.Context) error {
+			return operator.Stop(ctx, topo, stopOpt)
+		}).
+		ParallelStep("+ Restore data of instances", false, restoreTasks...).
+		Func("StartCluster", func(ctx context.Context) error {
+			return operator.Start(ctx, topo, stopOpt, tlsCfg)
+		}).
+		Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := t.Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return err
+		}
+		return errors.WithStack(err)
+	}
+
+	m.logger.Infof("Restored cluster `%s` from backup %s successfully", name, manifest.BackupID)
+	return nil
+}
+
+// restoreDataDir replaces the content of the data dir of the instance with the archive
+func restoreDataDir(ctx context.Context, file *BackupFile, dataDir, deployDir, backupDir string, onHost bool) error {
+	e, ok := ctxt.GetInner(ctx).GetExecutor(file.Host)
+	if !ok {
+		return task.ErrNoExecutor
+	}
+
+	archive := file.Path
+	if !onHost {
+		// the archive is pushed to the deploy dir before extracted
+		archive = path.Join(deployDir, file.Path)
+		if err := e.Transfer(ctx, filepath.Join(backupDir, file.Path), archive, false, 0, false); err != nil {
+			return err
+		}
+		defer func() {
+			_, _, _ = e.Execute(ctx, fmt.Sprintf("rm -f %s", archive), false)
+		}()
+	} else {
+		stdout, stderr, err := e.Execute(ctx, fmt.Sprintf("sha256sum %s", archive), false)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to check %s on %s: %s", archive, file.Host, strings.TrimSpace(string(stderr)))
+		}
+		if fields := strings.Fields(string(stdout)); len(fields) == 0 || fields[0] != file.SHA256 {
+			return errors.Errorf("the checksum of %s on %s does not match the manifest", archive, file.Host)
+		}
+	}
+
+	cmd := fmt.Sprintf("mkdir -p %[1]s && find %[1]s -mindepth 1 -delete && tar -xzf %[2]s -C %[1]s", dataDir, archive)
+	if _, stderr, err := e.Execute(ctx, cmd, false); err != nil {
+		return errors.WithMessagef(err, "failed to restore %s on %s: %s", dataDir, file.Host, strings.TrimSpace(string(stderr)))
+	}
+	return nil
+}
+
+// readBackupManifest reads the manifest in the backup dir
+func readBackupManifest(dir string) (*BackupManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, BackupManifestName))
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to read the manifest of the backup in %s", dir)
+	}
+	manifest := &BackupManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, errors.WithMessagef(err, "failed to parse the manifest of the backup in %s", dir)
+	}
+	return manifest, nil
+}
+
+// verifyBackupFile checks the checksum of the local backup file
+func verifyBackupFile(dir string, file *BackupFile) error {
+	f, err := os.Open(filepath.Join(dir, file.Path))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	if err := utils.CheckSHA256(f, file.SHA256); err != nil {
+		return errors.WithMessagef(err, "the backup file %s is broken", file.Path)
+	}
+	return nil
+}
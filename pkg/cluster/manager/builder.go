@@ -57,6 +57,7 @@ func buildEnvInitTasks(topo spec.Topology, opt *InstallOptions, gOpt *operator.O
 				sshConnProps.IdentityFilePassphrase,
 				gOpt.SSHTimeout,
 				gOpt.OptTimeout,
+				gOpt.SSHType,
 			).
 			UserAction(host, globalOptions.User, globalOptions.Group, opt.SkipCreateUser || globalOptions.User == opt.User).
 			EnvInit(host, globalOptions.User, globalOptions.Group).
@@ -123,6 +124,7 @@ func buildMonitoredDeployTask(
 					p.IdentityFilePassphrase,
 					gOpt.SSHTimeout,
 					gOpt.OptTimeout,
+					gOpt.SSHType,
 				).
 				//t := task.NewSimpleUerSSH(m.logger, inst.GetManageHost(), inst.GetSSHPort(), globalOptions.User, 0, 0).
 				Mkdir(globalOptions.User, host, deployDirs...).
@@ -164,6 +166,7 @@ func buildInitMonitoredConfigTasks(
 	sshTimeout, exeTimeout uint64,
 	gOpt operator.Options,
 	p *gui.SSHConnectionProps,
+	backup bool,
 ) []*task.StepDisplay {
 	if monitoredOptions == nil || !monitoredOptions.TSMonitorEnabled {
 		return nil
@@ -192,6 +195,7 @@ func buildInitMonitoredConfigTasks(
 					p.IdentityFilePassphrase,
 					gOpt.SSHTimeout,
 					gOpt.OptTimeout,
+					gOpt.SSHType,
 				).
 				MonitoredConfig(
 					clusterName,
@@ -207,6 +211,7 @@ func buildInitMonitoredConfigTasks(
 						Log:    logDir,
 						Cache:  specManager.Path(clusterName, spec.TempConfigPath),
 					},
+					backup,
 				).BuildAsStep(fmt.Sprintf("  - Generate config %s -> %s", comp, host))
 
 			//t := task.NewSimpleUerSSH(logger, host, info.ssh, globalOptions.User, gOpt, p, globalOptions.SSHType).
@@ -285,6 +290,7 @@ func buildMkdirTasks(topo spec.Topology, gOpt *operator.Options, sshConnProps *g
 				sshConnProps.IdentityFilePassphrase,
 				gOpt.SSHTimeout,
 				gOpt.OptTimeout,
+				gOpt.SSHType,
 			).
 			//t := task.NewSimpleUerSSH(m.logger, inst.GetManageHost(), inst.GetSSHPort(), globalOptions.User, 0, 0).
 			Mkdir(globalOptions.User, inst.GetManageHost(), deployDirs...).
@@ -338,6 +344,7 @@ func buildDeployTasks(clusterName, clusterVersion string, topo spec.Topology, gO
 				sshConnProps.IdentityFilePassphrase,
 				gOpt.SSHTimeout,
 				gOpt.OptTimeout,
+				gOpt.SSHType,
 			)
 		//t := task.NewSimpleUerSSH(m.logger, inst.GetManageHost(), inst.GetSSHPort(), globalOptions.User, 0, 0).
 
@@ -369,6 +376,65 @@ func buildDeployTasks(clusterName, clusterVersion string, topo spec.Topology, gO
 	return deployCompTasks
 }
 
+// buildCertificateTasks generates certificate for the instances and transfers it to the server,
+// sshConnProps is nil if the executors of the instances are already set, e.g. by sshTaskBuilder.
+func buildCertificateTasks(
+	m *Manager,
+	clusterName string,
+	topo spec.Topology,
+	base *spec.BaseMeta,
+	gOpt operator.Options,
+	sshConnProps *gui.SSHConnectionProps,
+) ([]*task.StepDisplay, error) {
+	if !topo.BaseTopo().GlobalOptions.TLSEnabled {
+		return nil, nil
+	}
+
+	ca, err := m.readClusterCA(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	roleFilter := set.NewStringSet(tlsComponents...)
+	var certificateTasks []*task.StepDisplay
+	topo.IterInstance(func(inst spec.Instance) {
+		if !roleFilter.Exist(inst.ComponentName()) {
+			return
+		}
+
+		paths := instanceDirPaths(m, clusterName, inst, base)
+		tb := task.NewBuilder(m.logger)
+		if sshConnProps != nil {
+			tb.RootSSH(
+				inst.GetManageHost(),
+				inst.GetSSHPort(),
+				base.User,
+				sshConnProps.Password,
+				sshConnProps.IdentityFile,
+				sshConnProps.IdentityFilePassphrase,
+				gOpt.SSHTimeout,
+				gOpt.OptTimeout,
+				gOpt.SSHType,
+			)
+		}
+		tb.
+			Mkdir(base.User, inst.GetManageHost(), filepath.Join(paths.Deploy, spec.TLSCertKeyDir)).
+			TLSCert(
+				inst.GetManageHost(),
+				inst.ComponentName(),
+				inst.Role(),
+				inst.GetPort(),
+				set.NewStringSet(inst.GetHost(), inst.GetManageHost()).Slice(),
+				ca,
+				m.specManager.Path(clusterName, spec.TLSCertKeyDir, spec.TLSCACert),
+				paths,
+			)
+		certificateTasks = append(certificateTasks,
+			tb.BuildAsStep(fmt.Sprintf("  - Generate certificate %s -> %s", inst.ComponentName(), inst.ID())))
+	})
+	return certificateTasks, nil
+}
+
 func buildInitConfigTasks(
 	m *Manager,
 	clustername string,
@@ -379,32 +445,44 @@ func buildInitConfigTasks(
 	var tasks []*task.StepDisplay
 
 	topo.IterInstance(func(instance spec.Instance) {
-		compName := instance.ComponentName()
-		deployDir := spec.Abs(base.User, instance.DeployDir())
-		// data dir would be empty for components which don't need it
-		dataDir := spec.Abs(base.User, instance.DataDir())
-		// log dir will always be with values, but might not be used by the component
-		logDir := spec.Abs(base.User, instance.LogDir())
+		tasks = append(tasks, buildInitConfigTask(m, clustername, instance, base, gOpt, false))
+	})
 
-		// Download and copy the latest component to remote if the cluster is imported from Ansible
-		tb := task.NewBuilder(m.logger)
-		t := tb.InitConfig(
+	return tasks
+}
+
+// buildInitConfigTask builds the task to generate the config of a single instance, the
+// overwritten files are restored on rollback if backup is set
+func buildInitConfigTask(
+	m *Manager,
+	clustername string,
+	instance spec.Instance,
+	base *spec.BaseMeta,
+	gOpt operator.Options,
+	backup bool,
+) *task.StepDisplay {
+	return task.NewBuilder(m.logger).
+		InitConfig(
 			clustername,
 			base.Version,
 			m.specManager,
 			instance,
 			base.User,
 			gOpt.IgnoreConfigCheck,
-			meta.DirPaths{
-				Deploy: deployDir,
-				Data:   dataDir,
-				Log:    logDir,
-				Cache:  m.specManager.Path(clustername, spec.TempConfigPath),
-			},
+			instanceDirPaths(m, clustername, instance, base),
+			backup,
 		).
-			BuildAsStep(fmt.Sprintf("  - Generate config %s -> %s", compName, instance.ID()))
-		tasks = append(tasks, t)
-	})
+		BuildAsStep(fmt.Sprintf("  - Generate config %s -> %s", instance.ComponentName(), instance.ID()))
+}
 
-	return tasks
+// instanceDirPaths returns the absolute directories of the instance used to generate configs
+func instanceDirPaths(m *Manager, clustername string, instance spec.Instance, base *spec.BaseMeta) meta.DirPaths {
+	return meta.DirPaths{
+		Deploy: spec.Abs(base.User, instance.DeployDir()),
+		// data dir would be empty for components which don't need it
+		Data: spec.Abs(base.User, instance.DataDir()),
+		// log dir will always be with values, but might not be used by the component
+		Log:   spec.Abs(base.User, instance.LogDir()),
+		Cache: m.specManager.Path(clustername, spec.TempConfigPath),
+	}
 }
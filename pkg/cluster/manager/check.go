@@ -15,10 +15,195 @@
 package manager
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	operator "github.com/openGemini/gemix/pkg/cluster/operation"
 	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/cluster/task"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/set"
 	"github.com/pkg/errors"
 )
 
+// CheckOptions contains the options for check command
+type CheckOptions struct {
+	User         string // username of login to the SSH server
+	IdentityFile string // path to the private key file
+	UsePassword  bool   // use password instead of identity file for ssh connection
+	ExistCluster bool   // check an existing cluster instead of a topology file
+	ApplyFix     bool   // try to apply fixes of failed checks
+}
+
+// CheckCluster checks the hosts of a topology file or an existing cluster before
+// deploying, and tries to fix the failed items if ApplyFix is set.
+func (m *Manager) CheckCluster(clusterOrTopoName string, opt CheckOptions, gOpt operator.Options) error {
+	var topo spec.Topology
+	var b *task.Builder
+
+	if opt.ExistCluster {
+		metadata, err := m.meta(clusterOrTopoName)
+		if err != nil {
+			return err
+		}
+		topo = metadata.GetTopology()
+		if b, err = m.sshTaskBuilder(clusterOrTopoName, topo, metadata.GetBaseMeta().User, gOpt); err != nil {
+			return err
+		}
+	} else {
+		topo = m.specManager.NewMetadata().GetTopology()
+		if err := spec.ParseTopologyYaml(clusterOrTopoName, topo); err != nil {
+			return errors.WithStack(err)
+		}
+		spec.ExpandRelativeDir(topo)
+
+		sshConnProps, err := gui.ReadIdentityFileOrPassword(opt.IdentityFile, opt.UsePassword)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if err = m.fillHost(sshConnProps, topo, opt.User); err != nil {
+			return errors.WithStack(err)
+		}
+
+		b = task.NewBuilder(m.logger)
+		for host, info := range getAllUniqueHosts(topo) {
+			b.RootSSH(
+				host,
+				info.Ssh,
+				opt.User,
+				sshConnProps.Password,
+				sshConnProps.IdentityFile,
+				sshConnProps.IdentityFilePassphrase,
+				gOpt.SSHTimeout,
+				gOpt.OptTimeout,
+				gOpt.SSHType,
+			)
+		}
+	}
+
+	hostOpts := getCheckHostOptions(topo, opt.ExistCluster)
+	hosts := make([]string, 0, len(hostOpts))
+	for host := range hostOpts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	var checkTasks []*task.StepDisplay
+	for _, host := range hosts {
+		host := host
+		hostOpt := hostOpts[host]
+		t := task.NewBuilder(m.logger).
+			Func("CheckHost", func(ctx context.Context) error {
+				results := operator.CheckHost(ctx, host, hostOpt)
+				if opt.ApplyFix {
+					operator.FixHost(ctx, host, hostOpt, results)
+				}
+				items := make([]any, 0, len(results))
+				for _, r := range results {
+					items = append(items, r)
+				}
+				ctxt.GetInner(ctx).SetCheckResults(host, items)
+				return nil
+			}).
+			BuildAsStep(fmt.Sprintf("  - Checking node %s", host))
+		checkTasks = append(checkTasks, t)
+	}
+
+	t := b.ParallelStep("+ Check system requirements", false, checkTasks...).Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := t.Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return err
+		}
+		return errors.WithStack(err)
+	}
+
+	// print the results of all the hosts
+	resultTable := [][]string{{"Node", "Check", "Result", "Message"}}
+	var failed, warned int
+	for _, host := range hosts {
+		items, _ := ctxt.GetInner(ctx).GetCheckResults(host)
+		for _, item := range items {
+			r := item.(*operator.CheckResult)
+			status := r.Status()
+			switch status {
+			case operator.CheckStatusPass:
+				status = color.GreenString(status)
+			case operator.CheckStatusWarn:
+				warned++
+				status = color.YellowString(status)
+			case operator.CheckStatusFail:
+				failed++
+				status = color.HiRedString(status)
+			default:
+				status = color.CyanString(status)
+			}
+			resultTable = append(resultTable, []string{host, r.Name, status, r.String()})
+		}
+	}
+	gui.PrintTable(resultTable, true)
+
+	if failed > 0 || warned > 0 {
+		msg := fmt.Sprintf("%d check items failed and %d check items have warnings", failed, warned)
+		if !opt.ApplyFix {
+			cmd := "gemix cluster check " + clusterOrTopoName + " --apply"
+			msg += fmt.Sprintf(", some of them can be fixed by `%s`", color.New(color.FgHiBlue).Sprint(cmd))
+		}
+		m.logger.Warnf("%s", msg)
+	} else {
+		m.logger.Infof("All check items passed")
+	}
+	return nil
+}
+
+// getCheckHostOptions returns the check options of each host in the topology
+func getCheckHostOptions(topo spec.Topology, existCluster bool) map[string]*operator.CheckHostOptions {
+	global := topo.BaseTopo().GlobalOptions
+	hostOpts := make(map[string]*operator.CheckHostOptions)
+	dataDirs := make(map[string]set.StringSet)
+
+	topo.IterInstance(func(inst spec.Instance) {
+		host := inst.GetManageHost()
+		hostOpt, ok := hostOpts[host]
+		if !ok {
+			hostOpt = &operator.CheckHostOptions{
+				User:      global.User,
+				OS:        inst.OS(),
+				Arch:      inst.Arch(),
+				SkipPorts: existCluster, // the instances of the cluster are listening on the ports
+			}
+			if hostOpt.OS == "" {
+				hostOpt.OS = global.OS
+			}
+			if hostOpt.Arch == "" {
+				hostOpt.Arch = global.Arch
+			}
+			hostOpts[host] = hostOpt
+			dataDirs[host] = set.NewStringSet()
+		}
+
+		hostOpt.Ports = append(hostOpt.Ports, inst.UsedPorts()...)
+		for _, dir := range strings.Split(inst.DataDir(), ",") {
+			if dir != "" && !dataDirs[host].Exist(dir) {
+				dataDirs[host].Insert(dir)
+				hostOpt.DataDirs = append(hostOpt.DataDirs, dir)
+			}
+		}
+	})
+	return hostOpts
+}
+
 // checkConflict checks cluster conflict
 func checkConflict(m *Manager, clusterName string, topo spec.Topology) error {
 	clusterList, err := m.specManager.GetAllClusters()
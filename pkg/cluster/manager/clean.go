@@ -0,0 +1,205 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	operator "github.com/openGemini/gemix/pkg/cluster/operation"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/set"
+	"github.com/pkg/errors"
+)
+
+// auditLogDirName is the sub directory of ts-sql log dir to save the audit logs
+const auditLogDirName = "audit"
+
+// CleanCluster cleans the data, logs or audit logs of the cluster, the instances
+// whose files are deleted are stopped and can be started again by `start`.
+func (m *Manager) CleanCluster(name string, gOpt operator.Options, cleanOpt operator.Options, skipConfirm bool) error {
+	// check locked
+	if err := m.specManager.ScaleOutLockedErr(name); err != nil {
+		return err
+	}
+
+	if !cleanOpt.CleanupData && !cleanOpt.CleanupLog && !cleanOpt.CleanupAuditLog {
+		return errors.New("nothing to clean, please specify at least one of --data, --log, --audit-log or --all")
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+
+	// calculate file paths to be deleted before the prompt
+	delFileMap, delDirMap, affected := getCleanupFiles(topo, cleanOpt)
+	if len(affected) == 0 {
+		m.logger.Infof("No instance to clean in cluster `%s`", name)
+		return nil
+	}
+
+	if !skipConfirm {
+		if err := cleanupConfirm(name, cleanOpt, delFileMap, delDirMap); err != nil {
+			return err
+		}
+	}
+
+	stopOpt := gOpt
+	stopOpt.Roles = nil
+	stopOpt.Nodes = affected.Slice()
+
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return err
+	}
+	t := b.
+		Func("StopCluster", func(ctx context.Context) error {
+			return operator.Stop(ctx, topo, stopOpt)
+		}).
+		Func("CleanupCluster", func(ctx context.Context) error {
+			return operator.CleanupComponent(ctx, delFileMap, delDirMap)
+		}).
+		Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := t.Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return err
+		}
+		return errors.WithStack(err)
+	}
+
+	hint := color.New(color.FgHiBlue).Sprintf("gemix cluster start %s", name)
+	m.logger.Infof("Cleanup cluster `%s` successfully, you can start it with command: `%s`", name, hint)
+	return nil
+}
+
+// getCleanupFiles returns the files and the dirs whose contents are to be deleted on each
+// host, and the IDs of the instances whose files are deleted, the deploy dirs are kept.
+func getCleanupFiles(topo spec.Topology, cleanOpt operator.Options) (map[string]set.StringSet, map[string]set.StringSet, set.StringSet) {
+	delFileMap := make(map[string]set.StringSet)
+	delDirMap := make(map[string]set.StringSet)
+	affected := set.NewStringSet()
+	retainDataRoles := set.NewStringSet(cleanOpt.RetainDataRoles...)
+	retainDataNodes := set.NewStringSet(cleanOpt.RetainDataNodes...)
+
+	for _, comp := range topo.ComponentsByStopOrder() {
+		for _, ins := range comp.Instances() {
+			// the files of the instance are retained
+			if retainDataRoles.Exist(ins.ComponentName()) ||
+				retainDataNodes.Exist(ins.ID()) ||
+				retainDataNodes.Exist(ins.GetHost()) ||
+				retainDataNodes.Exist(ins.GetManageHost()) {
+				continue
+			}
+
+			delPaths := set.NewStringSet()
+			delDirs := set.NewStringSet()
+			if cleanOpt.CleanupData && len(ins.DataDir()) > 0 {
+				for _, dataDir := range strings.Split(ins.DataDir(), ",") {
+					delDirs.Insert(dataDir)
+				}
+			}
+			if cleanOpt.CleanupLog && len(ins.LogDir()) > 0 {
+				// the rotated and compressed logs like `*.log.1` and `*.log.gz` are
+				// included, the audit log dir of ts-sql is left to --audit-log
+				delPaths.Insert(path.Join(ins.LogDir(), "*.log*"))
+			}
+			if cleanOpt.CleanupAuditLog && len(ins.LogDir()) > 0 && ins.ComponentName() == spec.ComponentTSSql {
+				delDirs.Insert(path.Join(ins.LogDir(), auditLogDirName))
+			}
+			if len(delPaths) == 0 && len(delDirs) == 0 {
+				continue
+			}
+
+			affected.Insert(ins.ID())
+			host := ins.GetManageHost()
+			if len(delPaths) > 0 {
+				if _, ok := delFileMap[host]; !ok {
+					delFileMap[host] = set.NewStringSet()
+				}
+				delFileMap[host] = delFileMap[host].Join(delPaths)
+			}
+			if len(delDirs) > 0 {
+				if _, ok := delDirMap[host]; !ok {
+					delDirMap[host] = set.NewStringSet()
+				}
+				delDirMap[host] = delDirMap[host].Join(delDirs)
+			}
+		}
+	}
+	return delFileMap, delDirMap, affected
+}
+
+// cleanupConfirm prints the paths to be deleted and asks for confirmation
+func cleanupConfirm(clusterName string, cleanOpt operator.Options, delFileMap, delDirMap map[string]set.StringSet) error {
+	var targets []string
+	if cleanOpt.CleanupData {
+		targets = append(targets, "data")
+	}
+	if cleanOpt.CleanupLog {
+		targets = append(targets, "log")
+	}
+	if cleanOpt.CleanupAuditLog {
+		targets = append(targets, "audit log")
+	}
+
+	hosts := set.NewStringSet()
+	for host := range delFileMap {
+		hosts.Insert(host)
+	}
+	for host := range delDirMap {
+		hosts.Insert(host)
+	}
+
+	sortedHosts := hosts.Slice()
+	sort.Strings(sortedHosts)
+
+	fmt.Printf("The following files will be deleted:\n")
+	for _, host := range sortedHosts {
+		fmt.Printf("  %s:\n", color.CyanString(host))
+		dirs := delDirMap[host].Slice()
+		sort.Strings(dirs)
+		for _, d := range dirs {
+			fmt.Printf("    %s/ (all the contents)\n", d)
+		}
+		files := delFileMap[host].Slice()
+		sort.Strings(files)
+		for _, f := range files {
+			fmt.Printf("    %s\n", f)
+		}
+	}
+
+	return gui.PromptForConfirmOrAbortError(
+		"This operation will stop the affected instances and clean the %s of cluster %s.\nDo you want to continue? [y/N]:",
+		color.HiYellowString(strings.Join(targets, ", ")),
+		color.HiYellowString(clusterName))
+}
@@ -0,0 +1,206 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	"github.com/openGemini/gemix/pkg/cluster/operation"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/set"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// InstInfo represents an instance info
+type InstInfo struct {
+	ID        string        `json:"id"`
+	Role      string        `json:"role"`
+	Host      string        `json:"host"`
+	Ports     string        `json:"ports"`
+	OsArch    string        `json:"os_arch"`
+	Status    string        `json:"status"`
+	Since     time.Duration `json:"since"`
+	DataDir   string        `json:"data_dir"`
+	DeployDir string        `json:"deploy_dir"`
+}
+
+// Display cluster meta and topology.
+func (m *Manager) Display(name string, gOpt operation.Options) error {
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	base := metadata.GetBaseMeta()
+
+	cyan := color.New(color.FgCyan, color.Bold)
+	fmt.Printf("Cluster name:       %s\n", cyan.Sprint(name))
+	fmt.Printf("Cluster version:    %s\n", cyan.Sprint(base.Version))
+	fmt.Printf("Deploy user:        %s\n", cyan.Sprint(base.User))
+
+	clusterInstInfos, err := m.GetClusterTopology(name, gOpt)
+	if err != nil {
+		return err
+	}
+
+	clusterTable := [][]string{
+		// Header
+		{"ID", "Role", "Host", "Ports", "OS/Arch", "Status", "Since", "Data Dir", "Deploy Dir"},
+	}
+	for _, v := range clusterInstInfos {
+		clusterTable = append(clusterTable, []string{
+			color.CyanString(v.ID),
+			v.Role,
+			v.Host,
+			v.Ports,
+			v.OsArch,
+			formatInstanceStatus(v.Status),
+			formatInstanceSince(v.Since),
+			v.DataDir,
+			v.DeployDir,
+		})
+	}
+
+	gui.PrintTable(clusterTable, true)
+	fmt.Printf("Total nodes: %d\n", len(clusterTable)-1)
+
+	return nil
+}
+
+// GetClusterTopology get the topology of the cluster with the live status of each instance.
+func (m *Manager) GetClusterTopology(name string, gOpt operation.Options) ([]InstInfo, error) {
+	metadata, err := m.meta(name)
+	if err != nil {
+		return nil, err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Build().Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			return nil, err
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	tlsCfg, err := topo.TLSConfig(m.specManager.Path(name, spec.TLSCertKeyDir))
+	if err != nil {
+		return nil, err
+	}
+
+	comps := topo.ComponentsByStartOrder()
+	if s, ok := topo.(*spec.Specification); ok {
+		comps = append(comps, &spec.TSMonitorComponent{Topology: s})
+	}
+
+	roleFilter := set.NewStringSet(gOpt.Roles...)
+	nodeFilter := set.NewStringSet(gOpt.Nodes...)
+
+	var insts []spec.Instance
+	for _, comp := range operation.FilterComponent(comps, roleFilter) {
+		insts = append(insts, operation.FilterInstance(comp.Instances(), nodeFilter)...)
+	}
+
+	// the status of ts-store is derived from ts-meta
+	var tsMetaAddrs []string
+	for _, comp := range topo.ComponentsByStartOrder() {
+		if comp.Name() != spec.ComponentTSMeta {
+			continue
+		}
+		for _, ins := range comp.Instances() {
+			tsMetaAddrs = append(tsMetaAddrs, utils.JoinHostPort(ins.GetManageHost(), ins.GetPort()))
+		}
+	}
+
+	clusterInstInfos := make([]InstInfo, len(insts))
+	timeout := time.Duration(gOpt.APITimeout) * time.Second
+
+	wg := sync.WaitGroup{}
+	for idx, ins := range insts {
+		wg.Add(1)
+		go func(idx int, ins spec.Instance) {
+			defer wg.Done()
+
+			dataDir := "-"
+			if ins.DataDir() != "" {
+				dataDir = ins.DataDir()
+			}
+
+			status := ins.Status(ctx, timeout, tlsCfg, tsMetaAddrs...)
+			var since time.Duration
+			if strings.HasPrefix(status, "Up") {
+				since = ins.Uptime(ctx, timeout, tlsCfg)
+			}
+
+			roleName := ins.ComponentName()
+			if ins.IsPatched() {
+				roleName += " (patched)"
+			}
+
+			clusterInstInfos[idx] = InstInfo{
+				ID:        ins.ID(),
+				Role:      roleName,
+				Host:      ins.GetHost(),
+				Ports:     strings.Trim(strings.Replace(fmt.Sprint(ins.UsedPorts()), " ", "/", -1), "[]"),
+				OsArch:    fmt.Sprintf("%s/%s", ins.OS(), ins.Arch()),
+				Status:    status,
+				Since:     since,
+				DataDir:   dataDir,
+				DeployDir: ins.DeployDir(),
+			}
+		}(idx, ins)
+	}
+	wg.Wait()
+
+	return clusterInstInfos, nil
+}
+
+func formatInstanceStatus(status string) string {
+	switch {
+	case strings.HasPrefix(status, "Up"):
+		return color.GreenString(status)
+	case strings.HasPrefix(status, "Down"), strings.HasPrefix(status, "Offline"):
+		return color.RedString(status)
+	default:
+		return status
+	}
+}
+
+func formatInstanceSince(since time.Duration) string {
+	if since <= 0 {
+		return "-"
+	}
+	return since.Round(time.Second).String()
+}
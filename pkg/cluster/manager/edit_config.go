@@ -0,0 +1,166 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// EditConfigOptions contains the options for config edition.
+type EditConfigOptions struct {
+	NewTopoFile string // path to new topology file to substitute the original one
+	DryRun      bool   // show the changes without saving them
+}
+
+// EditConfig lets the user edit the cluster's config.
+func (m *Manager) EditConfig(name string, opt EditConfigOptions, skipConfirm bool) error {
+	// check locked
+	if err := m.specManager.ScaleOutLockedErr(name); err != nil {
+		return err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	topo := metadata.GetTopology()
+
+	data, err := yaml.Marshal(topo)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	newTopo, err := m.editTopo(topo, data, opt, skipConfirm)
+	if err != nil {
+		return err
+	}
+
+	if newTopo == nil {
+		return nil
+	}
+
+	if opt.DryRun {
+		m.logger.Infof("The changes are not applied in dry run mode")
+		return nil
+	}
+
+	m.logger.Infof("Applying changes...")
+	clusterMeta := metadata.(*spec.ClusterMeta)
+	clusterMeta.SetTopology(newTopo)
+	if err = m.specManager.SaveMeta(name, clusterMeta); err != nil {
+		return errors.WithMessage(err, "failed to save meta")
+	}
+
+	hint := color.New(color.FgHiBlue).Sprintf("gemix cluster reload %s [-N <nodes>] [-R <roles>]", name)
+	m.logger.Infof("Applied successfully, please use `%s` to reload config.", hint)
+	return nil
+}
+
+// editTopo opens the topology in the editor, or reads it from the file specified, and
+// returns the new topology after checking. A nil topology is returned if nothing changed.
+func (m *Manager) editTopo(origTopo spec.Topology, data []byte, opt EditConfigOptions, skipConfirm bool) (spec.Topology, error) {
+	var name string
+	if opt.NewTopoFile == "" {
+		file, err := os.CreateTemp(os.TempDir(), "gemix-topology-*.yaml")
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		name = file.Name()
+		defer os.Remove(name)
+
+		_, err = file.Write(data)
+		file.Close()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if err = utils.OpenFileInEditor(name); err != nil {
+			return nil, err
+		}
+	} else {
+		name = opt.NewTopoFile
+	}
+
+	newData, err := os.ReadFile(name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// retry is used to continue editing on failure, the new topology is discarded if
+	// the topology is read from file or the user gives up
+	retry := func(reason error) (spec.Topology, error) {
+		if opt.NewTopoFile == "" {
+			fmt.Print(color.RedString("New topology could not be saved: "))
+			m.logger.Errorf("%s", reason)
+			if pass, _ := gui.PromptForConfirmNo("Do you want to continue editing? [Y/n]: "); !pass {
+				return m.editTopo(origTopo, newData, opt, skipConfirm)
+			}
+			m.logger.Infof("Nothing changed.")
+			return nil, nil
+		}
+		return nil, reason
+	}
+
+	// the topology is validated by Specification.Validate while unmarshalling
+	newTopo := m.specManager.NewMetadata().GetTopology()
+	decoder := yaml.NewDecoder(bytes.NewReader(newData))
+	decoder.SetStrict(true)
+	if err = decoder.Decode(newTopo); err != nil {
+		return retry(errors.WithMessage(err, "failed to parse topology"))
+	}
+
+	// report error if immutable field has been changed
+	if err = spec.ValidateSpecDiff(origTopo, newTopo); err != nil {
+		return retry(err)
+	}
+
+	origData, err := yaml.Marshal(origTopo)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	changedData, err := yaml.Marshal(newTopo)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if bytes.Equal(origData, changedData) {
+		m.logger.Infof("The file has nothing changed")
+		return nil, nil
+	}
+
+	if err = utils.ShowDiff(string(origData), string(changedData), os.Stdout); err != nil {
+		return nil, err
+	}
+
+	if !skipConfirm {
+		if err := gui.PromptForConfirmOrAbortError(
+			color.HiYellowString("Please check change highlight above, do you want to apply the change? [y/N]:"),
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	return newTopo, nil
+}
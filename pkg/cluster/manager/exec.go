@@ -0,0 +1,170 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	operator "github.com/openGemini/gemix/pkg/cluster/operation"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/cluster/task"
+	"github.com/openGemini/gemix/pkg/set"
+	"github.com/pkg/errors"
+)
+
+// ExecOptions for exec shell command.
+type ExecOptions struct {
+	Command string
+	Sudo    bool
+}
+
+// ExecResult is the result of the command on a host
+type ExecResult struct {
+	Host   string `json:"host"`
+	Stdout string `json:"stdout"`
+	Stderr string `json:"stderr"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Exec shell command on hosts in the cluster, the command is executed once on each host.
+func (m *Manager) Exec(name string, opt ExecOptions, gOpt operator.Options) error {
+	if opt.Command == "" {
+		return errors.New("no command is specified, please use --command to specify it")
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+
+	// filter the hosts by roles and nodes
+	roleFilter := set.NewStringSet(gOpt.Roles...)
+	nodeFilter := set.NewStringSet(gOpt.Nodes...)
+	filteredHosts := set.NewStringSet()
+	topo.IterInstance(func(inst spec.Instance) {
+		if len(roleFilter) > 0 && !roleFilter.Exist(inst.ComponentName()) {
+			return
+		}
+		if len(nodeFilter) > 0 && !nodeFilter.Exist(inst.ID()) &&
+			!nodeFilter.Exist(inst.GetHost()) && !nodeFilter.Exist(inst.GetManageHost()) {
+			return
+		}
+		filteredHosts.Insert(inst.GetManageHost())
+	})
+
+	var hosts []string
+	for host := range getAllUniqueHosts(topo) {
+		if filteredHosts.Exist(host) {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		return errors.New("no host matches the specified roles and nodes")
+	}
+	sort.Strings(hosts)
+
+	results := make(map[string]*ExecResult, len(hosts))
+	var mu sync.Mutex
+
+	var shellTasks []task.Task
+	for _, host := range hosts {
+		host := host
+		shellTasks = append(shellTasks, task.NewBuilder(m.logger).
+			Func(fmt.Sprintf("Exec: host=%s", host), func(ctx context.Context) error {
+				e := ctxt.GetInner(ctx).Get(host)
+				stdout, stderr, err := e.Execute(ctx, opt.Command, opt.Sudo)
+				ctxt.GetInner(ctx).SetOutputs(host, stdout, stderr)
+
+				// the failure on a host is reported in the result, the others keep running
+				result := &ExecResult{Host: host, Stdout: string(stdout), Stderr: string(stderr)}
+				if err != nil {
+					result.Error = err.Error()
+				}
+				mu.Lock()
+				results[host] = result
+				mu.Unlock()
+				return nil
+			}).
+			Build())
+	}
+
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return err
+	}
+	t := b.Parallel(false, shellTasks...).Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := t.Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return err
+		}
+		return errors.WithStack(err)
+	}
+
+	var failed int
+	sorted := make([]*ExecResult, 0, len(hosts))
+	for _, host := range hosts {
+		if results[host].Error != "" {
+			failed++
+		}
+		sorted = append(sorted, results[host])
+	}
+
+	switch gOpt.DisplayMode {
+	case "json":
+		d, err := json.MarshalIndent(struct {
+			Command string        `json:"command"`
+			Results []*ExecResult `json:"results"`
+		}{opt.Command, sorted}, "", "  ")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fmt.Println(string(d))
+	default:
+		for _, r := range sorted {
+			fmt.Printf("Outputs of %s on %s:\n", color.CyanString(opt.Command), color.CyanString(r.Host))
+			if len(r.Stdout) > 0 {
+				fmt.Printf("%s:\n%s", color.GreenString("stdout"), r.Stdout)
+			}
+			if len(r.Stderr) > 0 {
+				fmt.Printf("%s:\n%s", color.RedString("stderr"), r.Stderr)
+			}
+			if r.Error != "" {
+				fmt.Printf("%s: %s\n", color.RedString("error"), r.Error)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return errors.Errorf("the command failed on %d of %d hosts", failed, len(hosts))
+	}
+	return nil
+}
@@ -0,0 +1,614 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/openGemini/gemix/pkg/cluster/config"
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	operator "github.com/openGemini/gemix/pkg/cluster/operation"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/cluster/task"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/utils"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// ImportOptions contains the options for import.
+type ImportOptions struct {
+	InstallOptions
+	TopoFile   string // path to the topology file describing the deployed cluster
+	LegacyFile string // path to the clusterOptions.json saved by `install2`
+	Version    string // version of the deployed cluster, read from the legacy file if empty
+}
+
+// ImportedInstance is the state of a deployed instance found on its host
+type ImportedInstance struct {
+	ID        string
+	Role      string
+	Host      string
+	Binary    string // the path of the deployed binary
+	Config    string // the config file found in the deploy dir
+	Service   string // the state reported by `systemctl is-active`
+	Listening bool   // the port of the instance is listened
+
+	manageHost string
+	deployDir  string
+	configs    map[string]any // the flattened items of the config file
+}
+
+// binaryPath returns the path of the binary in the deploy dir of the instance
+func (i *ImportedInstance) binaryPath() string {
+	return path.Join(i.deployDir, "bin", i.Role)
+}
+
+// copyDeployedBinary copies the binary found on the host to the deploy dir of the instance
+func copyDeployedBinary(ctx context.Context, inst *ImportedInstance, user string) error {
+	e, ok := ctxt.GetInner(ctx).GetExecutor(inst.manageHost)
+	if !ok {
+		return task.ErrNoExecutor
+	}
+	dst := inst.binaryPath()
+	cmd := fmt.Sprintf("mkdir -p %s && cp -p %s %s && chown %s %s", path.Dir(dst), inst.Binary, dst, user, dst)
+	if _, stderr, err := e.Execute(ctx, cmd, true); err != nil {
+		return errors.WithMessagef(err, "failed to copy %s on %s: %s", inst.Binary, inst.Host, strings.TrimSpace(string(stderr)))
+	}
+	return nil
+}
+
+// Import takes over a cluster deployed by hand or by `install2`, the topology is read from the
+// topology file or converted from the legacy options, the hosts are probed for the deployed
+// binaries and configs, and the SSH keys, configs, scripts and systemd units are generated so
+// that the cluster can be managed by the other commands. The items of the deployed configs are
+// kept in the instance configs. The instances are not restarted.
+func (m *Manager) Import(clusterName string, opt ImportOptions, skipConfirm bool, gOpt operator.Options) error {
+	if gOpt.DryRun {
+		return errors.New("import does not support --dry-run, the deployed instances are probed on the hosts")
+	}
+	if err := ValidateClusterNameOrError(clusterName); err != nil {
+		return errors.WithStack(err)
+	}
+
+	exist, err := m.specManager.Exist(clusterName)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if exist {
+		return errors.Errorf("cluster name '%s' is duplicated. Please specify another cluster name", clusterName)
+	}
+
+	metadata := m.specManager.NewMetadata()
+	topo := metadata.GetTopology()
+	base := topo.BaseTopo()
+
+	var sshConnProps *gui.SSHConnectionProps
+	var legacyBinDirs map[string]string
+	switch {
+	case opt.TopoFile != "" && opt.LegacyFile != "":
+		return errors.New("only one of the topology file and the legacy file can be specified")
+	case opt.TopoFile != "":
+		if opt.Version == "" {
+			return errors.New("the version of the cluster must be specified when importing from a topology file")
+		}
+		if err = spec.ParseTopologyYaml(opt.TopoFile, topo); err != nil {
+			return errors.WithStack(err)
+		}
+		if sshConnProps, err = gui.ReadIdentityFileOrPassword(opt.IdentityFile, opt.UsePassword); err != nil {
+			return errors.WithStack(err)
+		}
+	case opt.LegacyFile != "":
+		var ops utils.ClusterOptions
+		if sshConnProps, ops, err = readLegacyOptions(&opt); err != nil {
+			return err
+		}
+		y, err := config.ReadFromYaml(ops.YamlPath)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to read the legacy config %s", ops.YamlPath)
+		}
+		var legacyTopo *spec.Specification
+		legacyTopo, legacyBinDirs = legacyTopology(ops, y)
+		data, err := yaml.Marshal(legacyTopo)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		// the defaults are set and the topology is validated by unmarshaling
+		if err = yaml.UnmarshalStrict(data, topo); err != nil {
+			return errors.WithMessagef(err, "failed to convert the legacy config %s", ops.YamlPath)
+		}
+	default:
+		return errors.New("either the topology file or the legacy file must be specified")
+	}
+
+	if s, ok := topo.(*spec.Specification); ok {
+		// the monitoring components are deployed by gemix only
+		if len(s.Monitors) > 0 || len(s.Grafanas) > 0 || s.MonitoredOptions.TSMonitorEnabled {
+			return errors.New("the monitoring components can not be imported, please remove them from the topology and scale out them after the import")
+		}
+	}
+
+	spec.ExpandRelativeDir(topo)
+
+	if err = checkConflict(m, clusterName, topo); err != nil {
+		return errors.WithStack(err)
+	}
+	if err = m.fillHost(sshConnProps, topo, opt.User); err != nil {
+		return errors.WithStack(err)
+	}
+
+	globalOptions := base.GlobalOptions
+	metadata.SetUser(globalOptions.User)
+	metadata.SetVersion(opt.Version)
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+
+	// probe the deployed instances with the given credentials
+	insts, err := m.probeInstances(ctx, topo, legacyBinDirs, opt, gOpt, sshConnProps)
+	if err != nil {
+		return err
+	}
+	m.printImportedInstances(insts)
+
+	var missing []string
+	var copyTasks []*task.StepDisplay
+	for _, inst := range insts {
+		switch inst.Binary {
+		case "":
+			missing = append(missing, inst.ID)
+		case inst.binaryPath():
+		default:
+			// the binary of the legacy install is copied to the deploy dir of the instance
+			inst := inst
+			copyTasks = append(copyTasks, task.NewBuilder(m.logger).
+				Func("CopyBinary", func(ctx context.Context) error {
+					return copyDeployedBinary(ctx, inst, globalOptions.User)
+				}).
+				BuildAsStep(fmt.Sprintf("  - Copy %s -> %s", inst.Binary, inst.binaryPath())))
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("the binaries of %s are not found in their deploy dirs, please install the cluster instead", strings.Join(missing, ", "))
+	}
+
+	if !skipConfirm {
+		if err = m.confirmTopology(clusterName, opt.Version, topo); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if err = os.MkdirAll(m.specManager.Path(clusterName), 0750); err != nil {
+		return errorx.InitializationFailed.
+			Wrap(err, "Failed to create cluster metadata directory '%s'", m.specManager.Path(clusterName)).
+			WithProperty(gui.SuggestionFromString("Please check file system permissions and try again."))
+	}
+
+	// generate CA and client cert for TLS enabled cluster
+	if _, err = m.genAndSaveCertificate(clusterName, globalOptions); err != nil {
+		return err
+	}
+
+	envInitTasks := buildEnvInitTasks(topo, &opt.InstallOptions, &gOpt, sshConnProps, m.logger)
+	mkdirTasks := buildMkdirTasks(topo, &gOpt, sshConnProps, m.logger)
+	certificateTasks, err := buildCertificateTasks(m, clusterName, topo, metadata.GetBaseMeta(), gOpt, sshConnProps)
+	if err != nil {
+		return err
+	}
+	keepDeployedConfigs(topo, insts)
+	refreshConfigTasks := buildInitConfigTasks(m, clusterName, topo, metadata.GetBaseMeta(), gOpt)
+
+	builder := task.NewBuilder(m.logger).
+		Step("+ Generate SSH keys",
+			task.NewBuilder(m.logger).
+				SSHKeyGen(m.specManager.Path(clusterName, "ssh", "id_rsa")).
+				Build(),
+			m.logger).
+		ParallelStep("+ Initialize target host environments", false, envInitTasks...).
+		ParallelStep("+ Mkdir at target hosts", false, mkdirTasks...)
+	if len(copyTasks) > 0 {
+		builder.ParallelStep("+ Copy deployed binaries", false, copyTasks...)
+	}
+	if len(certificateTasks) > 0 {
+		builder.ParallelStep("+ Copy certificate to remote host", gOpt.Force, certificateTasks...)
+	}
+	builder.ParallelStep("+ Init instance configs", gOpt.Force, refreshConfigTasks...)
+
+	if err = builder.Build().Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return err
+		}
+		return errors.WithStack(err)
+	}
+
+	if err = m.specManager.SaveMeta(clusterName, metadata); err != nil {
+		return err
+	}
+
+	m.logger.Infof("Cluster `%s` imported successfully", clusterName)
+
+	var unmanaged []string
+	for _, inst := range insts {
+		if inst.Listening && inst.Service != "active" {
+			unmanaged = append(unmanaged, inst.ID)
+		}
+	}
+	hint := color.New(color.FgHiBlue).Sprintf("gemix cluster restart %s", clusterName)
+	if len(unmanaged) > 0 {
+		m.logger.Warnf("The instances %s are running outside systemd, please stop them in the way they were started and start them with command: `%s`",
+			strings.Join(unmanaged, ", "), hint)
+		return nil
+	}
+	m.logger.Infof("The new configs take effect after the cluster is restarted with command: `%s`", hint)
+	return nil
+}
+
+// readLegacyOptions reads the options saved by `install2`, the SSH options given in
+// the command line take precedence over the ones in the file
+func readLegacyOptions(opt *ImportOptions) (*gui.SSHConnectionProps, utils.ClusterOptions, error) {
+	ops, err := utils.LoadClusterOptionsFromFile(opt.LegacyFile)
+	if err != nil {
+		return nil, ops, errors.WithMessagef(err, "failed to read the legacy file %s", opt.LegacyFile)
+	}
+	if opt.Version == "" {
+		opt.Version = ops.Version
+	}
+	if opt.User == "" {
+		opt.User = ops.User
+	}
+	if opt.UsePassword || opt.IdentityFile != "" {
+		props, err := gui.ReadIdentityFileOrPassword(opt.IdentityFile, opt.UsePassword)
+		return props, ops, errors.WithStack(err)
+	}
+	switch ops.SshType {
+	case utils.SSH_PW:
+		return &gui.SSHConnectionProps{Password: ops.Password}, ops, nil
+	default:
+		opt.IdentityFile = ops.Key
+		props, err := gui.ReadIdentityFileOrPassword(opt.IdentityFile, false)
+		return props, ops, errors.WithStack(err)
+	}
+}
+
+// legacyTopology converts the config of `install2` to the topology. The binaries and configs
+// of the legacy install are in <deploy_dir>/<version>/{bin,etc} and the logs are in <log_dir>,
+// which are shared by the instances on the host, so every instance is given its own deploy
+// dir <deploy_dir>/<component> and log dir <log_dir>/<component>, and the dirs of the legacy
+// binaries are returned by legacyKey
+func legacyTopology(ops utils.ClusterOptions, y config.Yaml) (*spec.Specification, map[string]string) {
+	user := ops.User
+	binDirs := make(map[string]string)
+	deployDir := func(host, comp, dir string) string {
+		binDirs[legacyKey(host, comp)] = path.Join(spec.Abs(user, dir), ops.Version, utils.RemoteBinRelPath)
+		return path.Join(spec.Abs(user, dir), comp)
+	}
+	s := &spec.Specification{
+		GlobalOptions: spec.GlobalOptions{
+			User:      user,
+			SSHPort:   y.Global.SSHPort,
+			DeployDir: spec.Abs(user, y.Global.DeployDir),
+			LogDir:    spec.Abs(user, y.Global.LogDir),
+			OS:        y.Global.OS,
+			Arch:      y.Global.Arch,
+		},
+		ServerConfigs: spec.ServerConfigs{
+			TsMeta:  legacyConfig(y.ServerConfig.TsMeta, nil),
+			TsSql:   legacyConfig(y.ServerConfig.TsSql, nil),
+			TsStore: legacyConfig(y.ServerConfig.TsStore, nil),
+		},
+	}
+
+	for _, meta := range y.TsMeta {
+		s.TSMetaServers = append(s.TSMetaServers, &spec.TSMetaSpec{
+			Host:       meta.Host,
+			SSHPort:    meta.SSHPort,
+			DeployDir:  deployDir(meta.Host, spec.ComponentTSMeta, meta.DeployDir),
+			LogDir:     path.Join(spec.Abs(user, meta.LogDir), spec.ComponentTSMeta),
+			DataDir:    spec.Abs(user, meta.DataDir),
+			ClientPort: meta.ClientPort,
+			PeerPort:   meta.PeerPort,
+			RaftPort:   meta.RaftPort,
+			GossipPort: meta.GossipPort,
+			Config:     legacyConfig(meta.Config, s.ServerConfigs.TsMeta),
+		})
+	}
+	for _, sql := range y.TsSql {
+		s.TSSqlServers = append(s.TSSqlServers, &spec.TSSqlSpec{
+			Host:      sql.Host,
+			SSHPort:   sql.SSHPort,
+			DeployDir: deployDir(sql.Host, spec.ComponentTSSql, sql.DeployDir),
+			LogDir:    path.Join(spec.Abs(user, sql.LogDir), spec.ComponentTSSql),
+			Port:      sql.Port,
+			Config:    legacyConfig(sql.Config, s.ServerConfigs.TsSql),
+		})
+	}
+	for _, store := range y.TsStore {
+		cfg := legacyConfig(store.Config, s.ServerConfigs.TsStore)
+		if store.MetaDir != "" {
+			if cfg == nil {
+				cfg = make(map[string]any)
+			}
+			cfg["data.store-meta-dir"] = spec.Abs(user, store.MetaDir)
+		}
+		s.TSStoreServers = append(s.TSStoreServers, &spec.TSStoreSpec{
+			Host:       store.Host,
+			SSHPort:    store.SSHPort,
+			DeployDir:  deployDir(store.Host, spec.ComponentTSStore, store.DeployDir),
+			LogDir:     path.Join(spec.Abs(user, store.LogDir), spec.ComponentTSStore),
+			DataDir:    spec.Abs(user, store.DataDir),
+			IngestPort: store.IngestPort,
+			SelectPort: store.SelectPort,
+			GossipPort: store.GossipPort,
+			Config:     cfg,
+		})
+	}
+	return s, binDirs
+}
+
+// legacyKey is the key of the legacy binary dir of the component on the host
+func legacyKey(host, comp string) string {
+	return comp + "@" + host
+}
+
+// legacyConfig converts the legacy config struct to the flat config keys, the unset
+// values and the values same as the server configs are omitted
+func legacyConfig(v any, serverConfigs map[string]any) map[string]any {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var configs map[string]any
+	if err := yaml.Unmarshal(data, &configs); err != nil {
+		return nil
+	}
+	for k, v := range configs {
+		if v == nil || reflect.ValueOf(v).IsZero() || reflect.DeepEqual(serverConfigs[k], v) {
+			delete(configs, k)
+		}
+	}
+	if len(configs) == 0 {
+		return nil
+	}
+	return configs
+}
+
+// probeInstances checks the binaries, configs, services and ports of the instances on their hosts
+func (m *Manager) probeInstances(
+	ctx context.Context,
+	topo spec.Topology,
+	legacyBinDirs map[string]string,
+	opt ImportOptions,
+	gOpt operator.Options,
+	sshConnProps *gui.SSHConnectionProps,
+) ([]*ImportedInstance, error) {
+	var mu sync.Mutex
+	var insts []*ImportedInstance
+	var probeTasks []*task.StepDisplay
+	for host, info := range getAllUniqueHosts(topo) {
+		host := host
+		tb := task.NewBuilder(m.logger).
+			RootSSH(
+				host,
+				info.Ssh,
+				opt.User,
+				sshConnProps.Password,
+				sshConnProps.IdentityFile,
+				sshConnProps.IdentityFilePassphrase,
+				gOpt.SSHTimeout,
+				gOpt.OptTimeout,
+				gOpt.SSHType,
+			).
+			Func("ProbeInstances", func(ctx context.Context) error {
+				var hostInsts []spec.Instance
+				topo.IterInstance(func(inst spec.Instance) {
+					if inst.GetManageHost() == host {
+						hostInsts = append(hostInsts, inst)
+					}
+				})
+				for _, inst := range hostInsts {
+					probed, err := probeInstance(ctx, inst, legacyBinDirs[legacyKey(host, inst.ComponentName())])
+					if err != nil {
+						return err
+					}
+					mu.Lock()
+					insts = append(insts, probed)
+					mu.Unlock()
+				}
+				return nil
+			})
+		probeTasks = append(probeTasks, tb.BuildAsStep(fmt.Sprintf("  - Probe %s:%d", host, info.Ssh)))
+	}
+
+	t := task.NewBuilder(m.logger).
+		ParallelStep("+ Probe deployed instances", false, probeTasks...).
+		Build()
+	if err := t.Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			return nil, err
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	sort.Slice(insts, func(i, j int) bool {
+		if insts[i].Role != insts[j].Role {
+			return insts[i].Role < insts[j].Role
+		}
+		return insts[i].ID < insts[j].ID
+	})
+	return insts, nil
+}
+
+// legacyConfigNames are the config file names of the components in the legacy install
+var legacyConfigNames = map[string]string{
+	spec.ComponentTSMeta:  utils.RemoteMetaConfName,
+	spec.ComponentTSSql:   utils.RemoteSqlConfName,
+	spec.ComponentTSStore: utils.RemoteStoreConfName,
+}
+
+// probeInstance checks the deployed files and the state of the instance, the binary and
+// config are looked up in the dirs of the legacy install as well if legacyBinDir is set
+func probeInstance(ctx context.Context, inst spec.Instance, legacyBinDir string) (*ImportedInstance, error) {
+	e, ok := ctxt.GetInner(ctx).GetExecutor(inst.GetManageHost())
+	if !ok {
+		return nil, task.ErrNoExecutor
+	}
+
+	probed := &ImportedInstance{
+		ID:         inst.ID(),
+		Role:       inst.ComponentName(),
+		Host:       inst.GetHost(),
+		manageHost: inst.GetManageHost(),
+		deployDir:  inst.DeployDir(),
+	}
+
+	comp := inst.ComponentName()
+	bins := []string{probed.binaryPath()}
+	confs := []string{path.Join(probed.deployDir, "conf", comp+".toml")}
+	if legacyBinDir != "" {
+		bins = append(bins, path.Join(legacyBinDir, comp))
+		confs = append(confs, path.Join(path.Dir(legacyBinDir), utils.RemoteEtcRelPath, legacyConfigNames[comp]))
+	}
+
+	// every check prints a line of `key:value`, the command always succeeds
+	cmd := fmt.Sprintf("sh -c 'for f in %s; do test -x $f && echo binary:$f && break; done; for f in %s; do test -f $f && echo config:$f && break; done; echo service:$(systemctl is-active %s 2>/dev/null); true'",
+		strings.Join(bins, " "), strings.Join(confs, " "), inst.ServiceName())
+	stdout, stderr, err := e.Execute(ctx, cmd, false)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to probe %s: %s", inst.ID(), strings.TrimSpace(string(stderr)))
+	}
+
+	for _, line := range strings.Split(string(stdout), "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found {
+			continue
+		}
+		switch key {
+		case "binary":
+			probed.Binary = value
+		case "config":
+			probed.Config = value
+		case "service":
+			probed.Service = value
+		}
+	}
+
+	if probed.Config != "" {
+		stdout, stderr, err = e.Execute(ctx, "cat "+probed.Config, false)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to read %s on %s: %s", probed.Config, inst.GetHost(), strings.TrimSpace(string(stderr)))
+		}
+		var configs map[string]any
+		if _, err = toml.Decode(string(stdout), &configs); err != nil {
+			return nil, errors.WithMessagef(err, "failed to parse %s on %s", probed.Config, inst.GetHost())
+		}
+		probed.configs = spec.FlattenMap(configs)
+	}
+
+	stdout, _, err = e.Execute(ctx, "ss -ltn", false)
+	probed.Listening = err == nil && bytes.Contains(stdout, []byte(fmt.Sprintf(":%d ", inst.GetPort())))
+	return probed, nil
+}
+
+// keepDeployedConfigs adds the items of the config files found on the hosts to the instance
+// configs, so that the hand-tuned configs are kept when the config files are generated, the
+// items set in the topology take precedence
+func keepDeployedConfigs(topo spec.Topology, insts []*ImportedInstance) {
+	s, ok := topo.(*spec.Specification)
+	if !ok {
+		return
+	}
+	deployed := make(map[string]map[string]any)
+	for _, inst := range insts {
+		if len(inst.configs) > 0 {
+			deployed[inst.ID] = inst.configs
+		}
+	}
+	merge := func(id string, cfg, global map[string]any) map[string]any {
+		configs, ok := deployed[id]
+		if !ok {
+			return cfg
+		}
+		if cfg == nil {
+			cfg = make(map[string]any, len(configs))
+		}
+		set, global := spec.FlattenMap(cfg), spec.FlattenMap(global)
+		for k, v := range configs {
+			if _, ok := set[k]; ok {
+				continue
+			}
+			if _, ok := global[k]; ok {
+				continue
+			}
+			cfg[k] = v
+		}
+		return cfg
+	}
+	s.IterInstance(func(inst spec.Instance) {
+		switch inst := inst.(type) {
+		case *spec.TSMetaInstance:
+			ms := inst.InstanceSpec.(*spec.TSMetaSpec)
+			ms.Config = merge(inst.ID(), ms.Config, s.ServerConfigs.TsMeta)
+		case *spec.TSSqlInstance:
+			ss := inst.InstanceSpec.(*spec.TSSqlSpec)
+			ss.Config = merge(inst.ID(), ss.Config, s.ServerConfigs.TsSql)
+		case *spec.TSStoreInstance:
+			ss := inst.InstanceSpec.(*spec.TSStoreSpec)
+			ss.Config = merge(inst.ID(), ss.Config, s.ServerConfigs.TsStore)
+		}
+	})
+}
+
+// printImportedInstances prints the probed instances
+func (m *Manager) printImportedInstances(insts []*ImportedInstance) {
+	yesOrNo := func(ok bool) string {
+		if ok {
+			return color.GreenString("yes")
+		}
+		return color.RedString("no")
+	}
+
+	fmt.Println("Deployed instances:")
+	instTable := [][]string{
+		// Header
+		{"ID", "Role", "Host", "Binary", "Config", "Service", "Listening"},
+	}
+	for _, inst := range insts {
+		instTable = append(instTable, []string{
+			inst.ID,
+			inst.Role,
+			inst.Host,
+			orDash(inst.Binary),
+			orDash(inst.Config),
+			formatServiceActive(orDash(inst.Service)),
+			yesOrNo(inst.Listening),
+		})
+	}
+	gui.PrintTable(instTable, true)
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"time"
+
+	"github.com/openGemini/gemix/pkg/cluster/api"
+	"github.com/openGemini/gemix/pkg/cluster/operation"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/crypto/rand"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// the admin user created by `start --init`
+const initAdminUser = "admin"
+
+// enableAuth enables the authentication of ts-sql in the server configs, the meta is
+// saved by the caller once the admin user is created, no credential is saved in it.
+func enableAuth(metadata spec.Metadata) error {
+	topo, ok := metadata.GetTopology().(*spec.Specification)
+	if !ok {
+		return errors.New("the topology does not support initializing the password")
+	}
+	if topo.ServerConfigs.TsSql == nil {
+		topo.ServerConfigs.TsSql = make(map[string]any)
+	}
+	topo.ServerConfigs.TsSql["http.auth-enabled"] = true
+	return nil
+}
+
+// genAdminPassword generates a password with upper and lower letters, digits and symbols
+func genAdminPassword() (string, error) {
+	for {
+		password, err := rand.Password(18)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		// the letters are randomly picked from both upper and lower ones
+		if strings.ToLower(password) != password && strings.ToUpper(password) != password {
+			return password, nil
+		}
+	}
+}
+
+// initAdminUserPassword creates the admin user through the http api of ts-sql
+func initAdminUserPassword(ctx context.Context, topo spec.Topology, password string, gOpt operation.Options, tlsCfg *tls.Config) error {
+	var addrs []string
+	for _, s := range topo.(*spec.Specification).TSSqlServers {
+		addrs = append(addrs, utils.JoinHostPort(s.Host, s.Port))
+	}
+
+	client := api.NewTSSqlClient(ctx, addrs, time.Second*time.Duration(gOpt.APITimeout), tlsCfg)
+	// ts-sql may not be able to serve until it connects to ts-meta
+	return utils.Retry(func() error {
+		return client.CreateAdminUser(initAdminUser, password)
+	}, utils.RetryOption{
+		Delay:   time.Second * 2,
+		Timeout: time.Second * 30,
+	})
+}
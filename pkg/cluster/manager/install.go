@@ -135,6 +135,10 @@ func (m *Manager) Install(
 			Wrap(err, "Failed to create cluster metadata directory '%s'", m.specManager.Path(clusterName)).
 			WithProperty(gui.SuggestionFromString("Please check file system permissions and try again."))
 	}
+	if gOpt.DryRun {
+		// nothing is kept for the cluster in dry run mode
+		defer func() { _ = m.specManager.Remove(clusterName) }()
+	}
 
 	// Initialize environment
 
@@ -143,10 +147,9 @@ func (m *Manager) Install(
 	metadata.SetVersion(clusterVersion)
 
 	// generate CA and client cert for TLS enabled cluster
-	//_, err = m.genAndSaveCertificate(clusterName, globalOptions)
-	//if err != nil {
-	//	return err
-	//}
+	if _, err = m.genAndSaveCertificate(clusterName, globalOptions); err != nil {
+		return err
+	}
 
 	// tasks which are used to download components, download missing component
 	downloadCompTasks := buildDownloadCompTasks(clusterVersion, topo, m.logger)
@@ -161,10 +164,10 @@ func (m *Manager) Install(
 	deployCompTasks := buildDeployTasks(clusterName, clusterVersion, topo, &gOpt, sshConnProps, m.logger)
 
 	// generates certificate for instance and transfers it to the server
-	//certificateTasks, err := buildCertificateTasks(m, name, topo, metadata.GetBaseMeta(), gOpt, sshProxyProps)
-	//if err != nil {
-	//	return err
-	//}
+	certificateTasks, err := buildCertificateTasks(m, clusterName, topo, metadata.GetBaseMeta(), gOpt, sshConnProps)
+	if err != nil {
+		return err
+	}
 
 	refreshConfigTasks := buildInitConfigTasks(m, clusterName, topo, metadata.GetBaseMeta(), gOpt)
 
@@ -199,6 +202,7 @@ func (m *Manager) Install(
 		gOpt.OptTimeout,
 		gOpt,
 		sshConnProps,
+		false,
 	)
 
 	builder := task.NewBuilder(m.logger).
@@ -210,8 +214,11 @@ func (m *Manager) Install(
 		ParallelStep("+ Download openGemini components", false, downloadCompTasks...).
 		ParallelStep("+ Initialize target host environments", false, envInitTasks...).
 		ParallelStep("+ Mkdir at target hosts", false, mkdirTasks...).
-		ParallelStep("+ Deploy openGemini instance", false, deployCompTasks...).
-		//ParallelStep("+ Copy certificate to remote host", gOpt.Force, certificateTasks...).
+		ParallelStep("+ Deploy openGemini instance", false, deployCompTasks...)
+	if len(certificateTasks) > 0 {
+		builder.ParallelStep("+ Copy certificate to remote host", gOpt.Force, certificateTasks...)
+	}
+	builder.
 		ParallelStep("+ Init instance configs", gOpt.Force, refreshConfigTasks...).
 		ParallelStep("+ Init monitor configs", gOpt.Force, monitorConfigTasks...)
 
@@ -223,6 +230,11 @@ func (m *Manager) Install(
 		m.logger,
 	)
 	if err = t.Execute(ctx); err != nil {
+		err = m.rollback(ctx, t, gOpt, err)
+		if !gOpt.NoRollback {
+			// the cluster is not installed, nothing is kept for it
+			_ = m.specManager.Remove(clusterName)
+		}
 		if errorx.Cast(err) != nil {
 			// FIXME: Map possible task errors and give suggestions.
 			return errors.WithStack(err)
@@ -230,6 +242,10 @@ func (m *Manager) Install(
 		return errors.WithStack(err)
 	}
 
+	if gOpt.DryRun {
+		return nil
+	}
+
 	// FIXME: remove me if you finish
 	err = m.specManager.SaveMeta(clusterName, metadata)
 	if err != nil {
@@ -0,0 +1,128 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Cluster represents a cluster
+type Cluster struct {
+	Name       string         `json:"name" yaml:"name"`
+	User       string         `json:"user" yaml:"user"`
+	Version    string         `json:"version" yaml:"version"`
+	Path       string         `json:"path" yaml:"path"`
+	PrivateKey string         `json:"private_key" yaml:"private_key"`
+	Instances  map[string]int `json:"instances" yaml:"instances"`
+}
+
+// ListCluster list the clusters.
+func (m *Manager) ListCluster(format string) error {
+	clusters, err := m.GetClusterList()
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		d, err := json.MarshalIndent(struct {
+			Clusters []Cluster `json:"clusters"`
+		}{clusters}, "", "  ")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fmt.Println(string(d))
+	case "yaml":
+		d, err := yaml.Marshal(struct {
+			Clusters []Cluster `yaml:"clusters"`
+		}{clusters})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fmt.Print(string(d))
+	case "table", "":
+		clusterTable := [][]string{
+			// Header
+			{"Name", "User", "Version", "Path", "PrivateKey", "Instances"},
+		}
+		for _, v := range clusters {
+			clusterTable = append(clusterTable, []string{
+				v.Name,
+				v.User,
+				v.Version,
+				v.Path,
+				v.PrivateKey,
+				formatInstanceCount(v.Instances),
+			})
+		}
+		gui.PrintTable(clusterTable, true)
+	default:
+		return errors.Errorf("unsupported output format: %s, should be one of: json, yaml, table", format)
+	}
+	return nil
+}
+
+// GetClusterList get the clusters list.
+func (m *Manager) GetClusterList() ([]Cluster, error) {
+	clusters, err := m.specManager.GetAllClusters()
+	if err != nil {
+		return nil, err
+	}
+
+	clusterList := make([]Cluster, 0, len(clusters))
+	for name, metadata := range clusters {
+		base := metadata.GetBaseMeta()
+
+		instances := make(map[string]int)
+		metadata.GetTopology().IterInstance(func(inst spec.Instance) {
+			instances[inst.ComponentName()]++
+		})
+
+		clusterList = append(clusterList, Cluster{
+			Name:       name,
+			User:       base.User,
+			Version:    base.Version,
+			Path:       m.specManager.Path(name),
+			PrivateKey: m.specManager.Path(name, "ssh", "id_rsa"),
+			Instances:  instances,
+		})
+	}
+
+	// sort by cluster name
+	sort.Slice(clusterList, func(i, j int) bool {
+		return clusterList[i].Name < clusterList[j].Name
+	})
+
+	return clusterList, nil
+}
+
+// formatInstanceCount formats the instance number of each role in component starting order
+func formatInstanceCount(instances map[string]int) string {
+	var counts []string
+	for _, role := range spec.AllComponentNames() {
+		if n, ok := instances[role]; ok {
+			counts = append(counts, fmt.Sprintf("%s:%d", role, n))
+		}
+	}
+	return strings.Join(counts, ",")
+}
@@ -15,6 +15,7 @@
 package manager
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -116,9 +117,28 @@ func (m *Manager) sshTaskBuilder(name string, topo spec.Topology, user string, g
 			user,
 			gOpt.SSHTimeout,
 			gOpt.OptTimeout,
+			gOpt.SSHType,
 		), nil
 }
 
+// rollback undoes the changes made by the failed task t on the hosts unless
+// it's disabled by --no-rollback, the original error is returned.
+func (m *Manager) rollback(ctx context.Context, t task.Task, gOpt operator.Options, err error) error {
+	if gOpt.NoRollback || gOpt.DryRun {
+		return err
+	}
+
+	m.logger.Warnf("Rolling back the changes on the hosts, use --no-rollback to keep them")
+	if rbErr := t.Rollback(ctx); rbErr != nil {
+		m.logger.Errorf("Failed to roll back: %s, the changes may be left on the hosts", rbErr)
+	}
+	// the completed tasks are undone, so they cannot be skipped by --resume
+	if cpErr := task.DiscardCheckPoint(); cpErr != nil {
+		m.logger.Warnf("Failed to remove the checkpoint: %s", cpErr)
+	}
+	return err
+}
+
 // fillHost full host cpu-arch and kernel-name
 func (m *Manager) fillHost(s *gui.SSHConnectionProps, topo spec.Topology, user string) error {
 	hostArchOrOS := map[string]string{}
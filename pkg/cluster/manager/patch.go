@@ -0,0 +1,199 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	operator "github.com/openGemini/gemix/pkg/cluster/operation"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/cluster/task"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/set"
+	"github.com/pkg/errors"
+)
+
+// Patch replaces the binary of the instances specified by gOpt.Roles and gOpt.Nodes with
+// the one in the package, the instances are restarted one by one and marked as patched.
+func (m *Manager) Patch(name string, packagePath string, gOpt operator.Options, skipConfirm bool) error {
+	// check locked
+	if err := m.specManager.ScaleOutLockedErr(name); err != nil {
+		return err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+
+	tlsCfg, err := topo.TLSConfig(m.specManager.Path(name, spec.TLSCertKeyDir))
+	if err != nil {
+		return err
+	}
+
+	if len(gOpt.Roles) == 0 && len(gOpt.Nodes) == 0 {
+		return errors.New("the instances to patch must be specified with --role or --node")
+	}
+
+	var insts []spec.Instance
+	comps := operator.FilterComponent(topo.ComponentsByStartOrder(), set.NewStringSet(gOpt.Roles...))
+	for _, comp := range comps {
+		insts = append(insts, operator.FilterInstance(comp.Instances(), set.NewStringSet(gOpt.Nodes...))...)
+	}
+	if len(insts) == 0 {
+		return errors.New("no instance matches the specified roles and nodes")
+	}
+
+	// only the binary of one component can be patched at a time
+	component := insts[0].ComponentName()
+	for _, inst := range insts {
+		if inst.ComponentName() != component {
+			return errors.Errorf("cannot patch %s and %s at the same time, please specify the instances of one component",
+				component, inst.ComponentName())
+		}
+		if inst.ComponentSource() != spec.ComponentOpenGemini {
+			return errors.Errorf("cannot patch %s, only the binaries of openGemini components can be patched", inst.ID())
+		}
+	}
+
+	packagePath, err = filepath.Abs(packagePath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err = checkPatchPackage(packagePath, component); err != nil {
+		return err
+	}
+
+	if !skipConfirm {
+		ids := make([]string, 0, len(insts))
+		for _, inst := range insts {
+			ids = append(ids, inst.ID())
+		}
+		if err := gui.PromptForConfirmOrAbortError(
+			"This operation will replace the binary of %s with %s and restart %s.\nDo you want to continue? [y/N]:",
+			color.HiYellowString(component),
+			color.HiYellowString(packagePath),
+			color.HiYellowString(strings.Join(ids, ","))); err != nil {
+			return err
+		}
+		m.logger.Infof("Patching cluster...")
+	}
+
+	// backup and replace the binaries, the instances on the same host are handled serially
+	patched := set.NewStringSet()
+	hostTasks := make(map[string]*task.Builder)
+	for _, inst := range insts {
+		deployDir := spec.Abs(base.User, inst.DeployDir())
+		tb, ok := hostTasks[inst.GetManageHost()]
+		if !ok {
+			tb = task.NewBuilder(m.logger)
+		}
+		hostTasks[inst.GetManageHost()] = tb.
+			BackupComponent(inst.ComponentName(), base.Version, inst.GetManageHost(), deployDir).
+			PatchComponent(inst.ComponentName(), packagePath, inst.GetManageHost(), deployDir)
+		patched.Insert(inst.ID())
+	}
+
+	var patchTasks []*task.StepDisplay
+	for host, tb := range hostTasks {
+		patchTasks = append(patchTasks, tb.BuildAsStep(fmt.Sprintf("  - Backup and patch %s -> %s", component, host)))
+	}
+
+	restartOpt := gOpt
+	restartOpt.Roles = nil
+	restartOpt.Nodes = patched.Slice()
+
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return err
+	}
+	t := b.
+		ParallelStep("+ Backup and patch components", false, patchTasks...).
+		Func("RollingRestart", func(ctx context.Context) error {
+			return operator.RollingRestart(ctx, topo, restartOpt, tlsCfg)
+		}).
+		Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := t.Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return err
+		}
+		return errors.WithStack(err)
+	}
+
+	if gOpt.DryRun {
+		return nil
+	}
+
+	for _, inst := range insts {
+		inst.SetPatched(true)
+	}
+	clusterMeta := metadata.(*spec.ClusterMeta)
+	if err := m.specManager.SaveMeta(name, clusterMeta); err != nil {
+		return err
+	}
+
+	m.logger.Infof("Patched cluster `%s` successfully", name)
+	return nil
+}
+
+// checkPatchPackage checks that the binary of the component is in the package
+func checkPatchPackage(packagePath, component string) error {
+	f, err := os.Open(packagePath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return errors.WithMessagef(err, "%s is not a valid tar.gz package", packagePath)
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.WithMessagef(err, "%s is not a valid tar.gz package", packagePath)
+		}
+		if hdr.Typeflag == tar.TypeReg && filepath.Base(hdr.Name) == component {
+			return nil
+		}
+	}
+	return errors.Errorf("the binary of %s is not found in the package %s", component, packagePath)
+}
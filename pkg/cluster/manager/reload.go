@@ -0,0 +1,160 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	operator "github.com/openGemini/gemix/pkg/cluster/operation"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/cluster/task"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/set"
+	"github.com/pkg/errors"
+)
+
+// Reload regenerates the configs of the instances from the metadata and restarts the
+// instances one by one, the instances whose config is not changed are not restarted.
+func (m *Manager) Reload(name string, gOpt operator.Options, skipConfirm bool) error {
+	// check locked
+	if err := m.specManager.ScaleOutLockedErr(name); err != nil {
+		return err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+
+	tlsCfg, err := topo.TLSConfig(m.specManager.Path(name, spec.TLSCertKeyDir))
+	if err != nil {
+		return err
+	}
+
+	if !skipConfirm {
+		if err = gui.PromptForConfirmOrAbortError(
+			fmt.Sprintf("Will reload the cluster %s with nodes: %s, roles: %s.\nDo you want to continue? [y/N]:",
+				color.HiYellowString(name),
+				color.HiYellowString(strings.Join(gOpt.Nodes, ",")),
+				color.HiYellowString(strings.Join(gOpt.Roles, ",")),
+			),
+		); err != nil {
+			return err
+		}
+	}
+
+	var insts []spec.Instance
+	comps := operator.FilterComponent(topo.ComponentsByStartOrder(), set.NewStringSet(gOpt.Roles...))
+	for _, comp := range comps {
+		insts = append(insts, operator.FilterInstance(comp.Instances(), set.NewStringSet(gOpt.Nodes...))...)
+	}
+
+	// the instances whose rendered config differs from the one on the host
+	changed := set.NewStringSet()
+	var mu sync.Mutex
+
+	var refreshConfigTasks []*task.StepDisplay
+	for _, inst := range insts {
+		inst := inst
+		paths := instanceDirPaths(m, name, inst, base)
+		localPath := spec.ServerConfigCachePath(inst.ComponentName(), inst.GetHost(), inst.GetPort(), paths.Cache)
+		remotePath := spec.ServerConfigPath(inst.ComponentName(), paths.Deploy)
+
+		var current []byte
+		t := task.NewBuilder(m.logger).
+			Func("FetchConfig", func(ctx context.Context) error {
+				e := ctxt.GetInner(ctx).Get(inst.GetManageHost())
+				// the config may not exist on the host, it's treated as changed
+				stdout, _, err := e.Execute(ctx, fmt.Sprintf("cat %s", remotePath), false)
+				if err == nil {
+					current = stdout
+				}
+				return nil
+			}).
+			InitConfig(
+				name,
+				base.Version,
+				m.specManager,
+				inst,
+				base.User,
+				gOpt.IgnoreConfigCheck,
+				paths,
+				false,
+			).
+			Func("CompareConfig", func(ctx context.Context) error {
+				// the components without toml config are always restarted
+				rendered, err := os.ReadFile(localPath)
+				if err == nil && current != nil && bytes.Equal(rendered, current) {
+					return nil
+				}
+				mu.Lock()
+				changed.Insert(inst.ID())
+				mu.Unlock()
+				return nil
+			}).
+			BuildAsStep(fmt.Sprintf("  - Refresh config %s -> %s", inst.ComponentName(), inst.ID()))
+		refreshConfigTasks = append(refreshConfigTasks, t)
+	}
+
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return err
+	}
+
+	t := b.
+		ParallelStep("+ Refresh instance configs", gOpt.Force, refreshConfigTasks...).
+		Func("RollingRestart", func(ctx context.Context) error {
+			if len(changed) == 0 {
+				m.logger.Infof("The configs of all the instances are not changed, skip restarting")
+				return nil
+			}
+			for _, inst := range insts {
+				if !changed.Exist(inst.ID()) {
+					m.logger.Infof("The config of %s is not changed, skip restarting", inst.ID())
+				}
+			}
+			restartOpt := gOpt
+			restartOpt.Nodes = changed.Slice()
+			return operator.RollingRestart(ctx, topo, restartOpt, tlsCfg)
+		}).
+		Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := t.Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return err
+		}
+		return errors.WithStack(err)
+	}
+
+	m.logger.Infof("Reloaded cluster `%s` successfully", name)
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	operator "github.com/openGemini/gemix/pkg/cluster/operation"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/cluster/task"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/set"
+	"github.com/pkg/errors"
+)
+
+// ScaleIn removes the instances specified by gOpt.Nodes from the cluster.
+func (m *Manager) ScaleIn(name string, skipConfirm bool, gOpt operator.Options) error {
+	if err := m.specManager.ScaleOutLockedErr(name); err != nil {
+		return err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+
+	tlsCfg, err := topo.TLSConfig(m.specManager.Path(name, spec.TLSCertKeyDir))
+	if err != nil {
+		return err
+	}
+
+	s, ok := topo.(*spec.Specification)
+	if !ok {
+		return errors.Errorf("unsupported topology of cluster `%s`", name)
+	}
+
+	nodes := set.NewStringSet(gOpt.Nodes...)
+	if len(nodes) == 0 {
+		return errors.New("no instance is specified, please use --node to specify the instances to scale in")
+	}
+
+	existing := set.NewStringSet()
+	removedRoles := set.NewStringSet()
+	var metaCount, removedMetaCount int
+	topo.IterInstance(func(inst spec.Instance) {
+		existing.Insert(inst.ID())
+		if inst.ComponentName() == spec.ComponentTSMeta {
+			metaCount++
+		}
+		if nodes.Exist(inst.ID()) {
+			removedRoles.Insert(inst.ComponentName())
+			if inst.ComponentName() == spec.ComponentTSMeta {
+				removedMetaCount++
+			}
+		}
+	})
+	if missing := nodes.Difference(existing); len(missing) > 0 {
+		return errors.Errorf("instance %s not found in cluster `%s`", strings.Join(missing.Slice(), ","), name)
+	}
+
+	// the surviving ts-meta instances must still be the majority of the raft group
+	if removedMetaCount > 0 && (metaCount-removedMetaCount)*2 <= metaCount {
+		return errors.Errorf("cannot scale in %d of %d ts-meta instances, the remaining raft members would lose quorum",
+			removedMetaCount, metaCount)
+	}
+
+	if !skipConfirm {
+		if err := gui.PromptForConfirmOrAbortError(
+			"This operation will delete the %s nodes in `%s` and all their data.\nDo you want to continue? [y/N]:",
+			strings.Join(nodes.Slice(), ","),
+			color.HiYellowString(name)); err != nil {
+			return err
+		}
+		m.logger.Infof("Scale-in nodes...")
+	}
+
+	newTopo := s.RemoveInstances(nodes)
+
+	// refresh the configs of the surviving instances which refer to the removed ones
+	var refreshConfigTasks []*task.StepDisplay
+	newTopo.IterInstance(func(inst spec.Instance) {
+		if needRefreshConfig(inst, removedRoles) {
+			refreshConfigTasks = append(refreshConfigTasks, buildInitConfigTask(m, name, inst, base, gOpt, false))
+		}
+	})
+
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return err
+	}
+	t := b.
+		Func("ScaleInCluster", func(ctx context.Context) error {
+			return operator.ScaleIn(ctx, topo, gOpt, tlsCfg)
+		}).
+		ParallelStep("+ Refresh instance configs", gOpt.Force, refreshConfigTasks...).
+		Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := t.Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return err
+		}
+		return errors.WithStack(err)
+	}
+
+	if gOpt.DryRun {
+		return nil
+	}
+
+	clusterMeta := metadata.(*spec.ClusterMeta)
+	clusterMeta.SetTopology(newTopo)
+	if err := m.specManager.SaveMeta(name, clusterMeta); err != nil {
+		return err
+	}
+
+	if len(refreshConfigTasks) > 0 {
+		hint := color.New(color.FgHiBlue).Sprintf("gemix cluster restart %s", name)
+		m.logger.Infof("The configs of the surviving instances are refreshed, they take effect after restarting: `%s`", hint)
+	}
+	m.logger.Infof("Scaled cluster `%s` in successfully", name)
+	return nil
+}
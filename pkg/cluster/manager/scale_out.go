@@ -0,0 +1,258 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	operator "github.com/openGemini/gemix/pkg/cluster/operation"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/cluster/task"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/set"
+	"github.com/pkg/errors"
+)
+
+// ScaleOut scales out the cluster with the instances in the topology file.
+func (m *Manager) ScaleOut(
+	name string,
+	topoFile string,
+	opt InstallOptions,
+	skipConfirm bool,
+	gOpt operator.Options,
+) error {
+	if err := m.specManager.ScaleOutLockedErr(name); err != nil {
+		return err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+
+	// the global options and server configs of the cluster are kept, only the servers are parsed
+	newPart := topo.NewPart()
+	if err = spec.ParseTopologyYaml(topoFile, newPart, true); err != nil {
+		return errors.WithStack(err)
+	}
+
+	newInstIDs := set.NewStringSet()
+	newRoles := set.NewStringSet()
+	newPart.IterInstance(func(inst spec.Instance) {
+		newInstIDs.Insert(inst.ID())
+		newRoles.Insert(inst.ComponentName())
+	})
+	if len(newInstIDs) < 1 {
+		return fmt.Errorf("no valid instance found in the input topology, please check your config")
+	}
+
+	spec.ExpandRelativeDir(newPart)
+
+	var sshConnProps *gui.SSHConnectionProps
+	if sshConnProps, err = gui.ReadIdentityFileOrPassword(opt.IdentityFile, opt.UsePassword); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err = m.fillHost(sshConnProps, newPart, opt.User); err != nil {
+		return errors.WithStack(err)
+	}
+
+	mergedTopo := topo.Merge(newPart)
+	if err = mergedTopo.Validate(); err != nil {
+		return errors.WithStack(err)
+	}
+	if err = checkConflict(m, name, mergedTopo); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if !skipConfirm {
+		if err = m.confirmTopology(name, base.Version, newPart); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	// the lock is kept if the scale-out fails without rolling back, so that
+	// the half-finished instances can be checked
+	if !gOpt.DryRun {
+		if err = m.specManager.NewScaleOutLock(name, newPart); err != nil {
+			return err
+		}
+	}
+
+	downloadCompTasks := buildDownloadCompTasks(base.Version, newPart, m.logger)
+	envInitTasks := buildEnvInitTasks(newPart, &opt, &gOpt, sshConnProps, m.logger)
+	mkdirTasks := buildMkdirTasks(newPart, &gOpt, sshConnProps, m.logger)
+	deployCompTasks := buildDeployTasks(name, base.Version, newPart, &gOpt, sshConnProps, m.logger)
+	certificateTasks, err := buildCertificateTasks(m, name, newPart, base, gOpt, sshConnProps)
+	if err != nil {
+		return err
+	}
+
+	// generate configs of the new instances, and refresh the configs of the existing
+	// instances which refer to the new ones, e.g. common.meta-join and gossip.members,
+	// the refreshed files are backed up to be restored on rollback
+	var newConfigTasks, refreshConfigTasks []*task.StepDisplay
+	mergedTopo.IterInstance(func(inst spec.Instance) {
+		if newInstIDs.Exist(inst.ID()) {
+			newConfigTasks = append(newConfigTasks, buildInitConfigTask(m, name, inst, base, gOpt, false))
+			return
+		}
+		if needRefreshConfig(inst, newRoles) {
+			refreshConfigTasks = append(refreshConfigTasks, buildInitConfigTask(m, name, inst, base, gOpt, true))
+		}
+	})
+
+	// ts-monitor is only deployed on the hosts which are new to the cluster,
+	// the configs are generated for all the hosts with new instances
+	var monitorConfigTasks []*task.StepDisplay
+	monitoredOptions := mergedTopo.GetMonitoredOptions()
+	if monitoredOptions != nil && monitoredOptions.TSMonitorEnabled {
+		oldHosts, _ := getMonitorHosts(topo)
+		newHosts, noAgentHosts := getMonitorHosts(newPart)
+		mergedHosts, _ := getMonitorHosts(mergedTopo)
+
+		deployHosts := make(map[string]*spec.MonitorHostInfo)
+		configHosts := make(map[string]*spec.MonitorHostInfo)
+		for host := range newHosts {
+			if _, found := oldHosts[host]; !found {
+				deployHosts[host] = newHosts[host]
+			}
+			configHosts[host] = mergedHosts[host]
+		}
+
+		dlTasks, dpTasks, err := buildMonitoredDeployTask(
+			m,
+			base.Version,
+			deployHosts,
+			noAgentHosts,
+			mergedTopo.BaseTopo().GlobalOptions,
+			monitoredOptions,
+			gOpt,
+			sshConnProps,
+		)
+		if err != nil {
+			return err
+		}
+		downloadCompTasks = append(downloadCompTasks, dlTasks...)
+		deployCompTasks = append(deployCompTasks, dpTasks...)
+
+		monitorConfigTasks = buildInitMonitoredConfigTasks(
+			m.specManager,
+			name,
+			configHosts,
+			noAgentHosts,
+			*mergedTopo.BaseTopo().GlobalOptions,
+			monitoredOptions,
+			m.logger,
+			gOpt.SSHTimeout,
+			gOpt.OptTimeout,
+			gOpt,
+			sshConnProps,
+			true,
+		)
+	}
+
+	tlsCfg, err := mergedTopo.TLSConfig(m.specManager.Path(name, spec.TLSCertKeyDir))
+	if err != nil {
+		return err
+	}
+
+	startOpt := gOpt
+	startOpt.Roles = nil
+	startOpt.Nodes = newInstIDs.Slice()
+
+	b := task.NewBuilder(m.logger).
+		SSHKeySet(
+			m.specManager.Path(name, "ssh", "id_rsa"),
+			m.specManager.Path(name, "ssh", "id_rsa.pub"),
+		).
+		ParallelStep("+ Download openGemini components", false, downloadCompTasks...).
+		ParallelStep("+ Initialize target host environments", false, envInitTasks...).
+		ParallelStep("+ Mkdir at target hosts", false, mkdirTasks...).
+		ParallelStep("+ Deploy openGemini instance", false, deployCompTasks...)
+	if len(certificateTasks) > 0 {
+		b.ParallelStep("+ Copy certificate to remote host", false, certificateTasks...)
+	}
+	t := b.
+		ClusterSSH(mergedTopo, base.User, gOpt.SSHTimeout, gOpt.OptTimeout, gOpt.SSHType).
+		ParallelStep("+ Init instance configs", gOpt.Force, newConfigTasks...).
+		ParallelStep("+ Refresh instance configs", gOpt.Force, refreshConfigTasks...).
+		ParallelStep("+ Init monitor configs", gOpt.Force, monitorConfigTasks...).
+		FuncWithRollback("StartNewInstances", func(ctx context.Context) error {
+			return operator.Start(ctx, mergedTopo, startOpt, tlsCfg)
+		}, func(ctx context.Context) error {
+			return operator.Stop(ctx, mergedTopo, startOpt)
+		}).
+		Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := t.Execute(ctx); err != nil {
+		err = m.rollback(ctx, t, gOpt, err)
+		if !gOpt.NoRollback && !gOpt.DryRun {
+			_ = m.specManager.ReleaseScaleOutLock(name)
+		}
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return err
+		}
+		return errors.WithStack(err)
+	}
+
+	if gOpt.DryRun {
+		return nil
+	}
+
+	clusterMeta := metadata.(*spec.ClusterMeta)
+	clusterMeta.SetTopology(mergedTopo)
+	if err := m.specManager.SaveMeta(name, clusterMeta); err != nil {
+		return err
+	}
+
+	if err := m.specManager.ReleaseScaleOutLock(name); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if len(refreshConfigTasks) > 0 {
+		hint := color.New(color.FgHiBlue).Sprintf("gemix cluster restart %s", name)
+		m.logger.Infof("The configs of the existing instances are refreshed, they take effect after restarting: `%s`", hint)
+	}
+	m.logger.Infof("Scaled cluster `%s` out successfully", name)
+	return nil
+}
+
+// needRefreshConfig checks whether the config of an existing instance refers to
+// the instances of the roles which are added or removed
+func needRefreshConfig(inst spec.Instance, changedRoles set.StringSet) bool {
+	switch inst.ComponentName() {
+	case spec.ComponentTSMeta, spec.ComponentTSSql, spec.ComponentTSStore:
+		// common.meta-join and gossip.members contain all the ts-meta instances
+		return changedRoles.Exist(spec.ComponentTSMeta)
+	case spec.ComponentGrafana:
+		// the data source of grafana is ts-server
+		return changedRoles.Exist(spec.ComponentTSServer)
+	}
+	return false
+}
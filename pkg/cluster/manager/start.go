@@ -23,6 +23,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/fatih/color"
 	"github.com/joomcode/errorx"
 	"github.com/openGemini/gemix/pkg/cluster/config"
 	"github.com/openGemini/gemix/pkg/cluster/ctxt"
@@ -34,14 +35,19 @@ import (
 	"golang.org/x/crypto/ssh"
 )
 
-// StartCluster start the cluster with specified name.
-func (m *Manager) StartCluster(name string, gOpt operation.Options, fn ...func(b *task.Builder, metadata spec.Metadata)) error {
+// StartCluster start the cluster with specified name, the authentication of ts-sql
+// is enabled and an admin user is created with a random password if initPasswd is set.
+func (m *Manager) StartCluster(name string, gOpt operation.Options, initPasswd bool, fn ...func(b *task.Builder, metadata spec.Metadata)) error {
 	m.logger.Infof("Starting cluster %s...", name)
 
+	if initPasswd && (len(gOpt.Roles) > 0 || len(gOpt.Nodes) > 0) {
+		return errors.New("the whole cluster must be started to initialize the password, --role and --node are not allowed")
+	}
+
 	// check locked
-	//if err := m.specManager.ScaleOutLockedErr(name); err != nil {
-	//	return err
-	//}
+	if err := m.specManager.ScaleOutLockedErr(name); err != nil {
+		return err
+	}
 
 	metadata, err := m.meta(name)
 	if err != nil {
@@ -51,24 +57,56 @@ func (m *Manager) StartCluster(name string, gOpt operation.Options, fn ...func(b
 	topo := metadata.GetTopology()
 	base := metadata.GetBaseMeta()
 
-	//tlsCfg, err := topo.TLSConfig(m.specManager.Path(name, spec.TLSCertKeyDir))
-	//if err != nil {
-	//	return err
-	//}
+	tlsCfg, err := topo.TLSConfig(m.specManager.Path(name, spec.TLSCertKeyDir))
+	if err != nil {
+		return err
+	}
 
 	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
 	if err != nil {
 		return err
 	}
 
+	var password string
+	if initPasswd {
+		if password, err = genAdminPassword(); err != nil {
+			return err
+		}
+		if err = enableAuth(metadata); err != nil {
+			return err
+		}
+
+		// the configs of ts-sql are refreshed to enable the authentication
+		var refreshConfigTasks []*task.StepDisplay
+		for _, inst := range (&spec.TSSqlComponent{Topology: topo.(*spec.Specification)}).Instances() {
+			refreshConfigTasks = append(refreshConfigTasks, buildInitConfigTask(m, name, inst, base, gOpt, false))
+		}
+		b.ParallelStep("+ Refresh ts-sql configs", false, refreshConfigTasks...)
+	}
+
 	b.Func("StartCluster", func(ctx context.Context) error {
-		return operation.Start(ctx, topo, gOpt, nil)
+		return operation.Start(ctx, topo, gOpt, tlsCfg)
 	})
 
+	if initPasswd {
+		// ts-sql may be running already, it's restarted to load the refreshed configs
+		restartOpt := gOpt
+		restartOpt.Roles = []string{spec.ComponentTSSql}
+		b.Func("RestartTSSql", func(ctx context.Context) error {
+			return operation.RollingRestart(ctx, topo, restartOpt, tlsCfg)
+		})
+	}
+
 	for _, f := range fn {
 		f(b, metadata)
 	}
 
+	if initPasswd {
+		b.Func("InitAdminUser", func(ctx context.Context) error {
+			return initAdminUserPassword(ctx, topo, password, gOpt, tlsCfg)
+		})
+	}
+
 	t := b.Build()
 
 	ctx := ctxt.New(
@@ -84,10 +122,31 @@ func (m *Manager) StartCluster(name string, gOpt operation.Options, fn ...func(b
 		return errors.WithStack(err)
 	}
 
+	if gOpt.DryRun {
+		return nil
+	}
+
 	m.logger.Infof("Started cluster `%s` successfully", name)
+
+	if initPasswd {
+		m.logger.Warnf("The authentication of ts-sql is enabled, and the admin user `%s` is created.", initAdminUser)
+		fmt.Printf("The password of `%s` is: '%s'.\n", initAdminUser, color.HiYellowString(password))
+		m.logger.Warnf("Copy and record it to somewhere safe, %s, and will not be stored.", color.HiRedString("it is only displayed once"))
+		m.logger.Warnf("The generated password %s.", color.HiRedString("can NOT be get and shown again"))
+
+		// the authentication is saved to the meta only after the admin user is created,
+		// the password is shown before so that it is not lost if saving fails
+		return m.specManager.SaveMeta(name, metadata.(*spec.ClusterMeta))
+	}
 	return nil
 }
 
+const CheckProcessCommand = "ps aux | grep -E '(ts-meta|ts-sql|ts-store)' | grep -v grep | awk '{print $11}'"
+
+func GenCheckPortCommand(port int) string {
+	return fmt.Sprintf("ss -tln | grep -q ':%d' && echo 'yes' || echo 'no'", port)
+}
+
 type Starter interface {
 	PrepareForStart() error
 	Start() error
@@ -15,380 +15,233 @@
 package manager
 
 import (
-	"errors"
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
+	"time"
 
-	"github.com/olekukonko/tablewriter"
-	"github.com/openGemini/gemix/pkg/cluster/config"
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	"github.com/openGemini/gemix/pkg/cluster/module"
 	"github.com/openGemini/gemix/pkg/cluster/operation"
-	"github.com/openGemini/gemix/utils"
-	"golang.org/x/crypto/ssh"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/set"
+	"github.com/pkg/errors"
 )
 
-const CheckProcessCommand = "ps aux | grep -E '(ts-meta|ts-sql|ts-store)' | grep -v grep | awk '{print $11}'"
-const CheckDiskCapacityCommand = "df -h | grep '^/dev/'"
-
-func GenCheckPortCommand(port int) string {
-	return fmt.Sprintf("ss -tln | grep -q ':%d' && echo 'yes' || echo 'no'", port)
-}
-
-type ClusterStatusPerServer struct {
-	Ip               string
-	RunningProcesses []string     // ts-meta,ts-sql,ts-store
-	PortOccupancy    map[int]bool // port->occupancy or not
-	DiskCapacity     []string     // disk->capacity
-}
-
-type StatusPatroller interface {
-	PrepareForPatrol() error
-	Patrol() error
-	Close()
+// InstanceStatus is the status of an instance collected on its host
+type InstanceStatus struct {
+	ID      string                `json:"id"`
+	Role    string                `json:"role"`
+	Host    string                `json:"host"`
+	Service string                `json:"service"`
+	Active  string                `json:"active"` // the state reported by `systemctl is-active`
+	Ports   []PortStatus          `json:"ports"`
+	DataDir string                `json:"data_dir"`
+	Disks   []operation.DiskUsage `json:"disks,omitempty"`
+	Errors  []string              `json:"errors,omitempty"`
 }
 
-type GeminiStatusPatroller struct {
-	version string
-	conf    *config.Config
-	// ip -> remotes
-	remotes map[string]*config.RemoteHost
-
-	// ip -> ssh clients
-	sshClients map[string]*ssh.Client
-
-	configurator config.Configurator // conf reader
-	executor     operation.Executor  // execute commands on remote host
-
-	clusterOptions utils.ClusterOptions
-
-	wg sync.WaitGroup
+// PortStatus is whether a port of the instance is listened
+type PortStatus struct {
+	Port      int  `json:"port"`
+	Listening bool `json:"listening"`
 }
 
-func NewGeminiStatusPatroller(ops utils.ClusterOptions) StatusPatroller {
-	return &GeminiStatusPatroller{
-		version:        ops.Version,
-		remotes:        make(map[string]*config.RemoteHost),
-		sshClients:     make(map[string]*ssh.Client),
-		configurator:   config.NewGeminiConfigurator(ops.YamlPath, "", ""),
-		clusterOptions: ops,
-	}
-}
-
-func (d *GeminiStatusPatroller) PrepareForPatrol() error {
-	var err error
-	if err = d.configurator.BuildConfig(); err != nil {
-		return err
-	}
-	d.conf = d.configurator.GetConfig()
-
-	// check the internet with all the remote servers
-	if err = d.prepareRemotes(d.conf); err != nil {
-		fmt.Printf("Failed to establish SSH connections with all remote servers. The specific error is: %s\n", err)
+// ClusterStatus checks the services, ports and disk usage of the instances on their hosts
+func (m *Manager) ClusterStatus(name string, gOpt operation.Options) error {
+	metadata, err := m.meta(name)
+	if err != nil {
 		return err
 	}
-	fmt.Println("Success to establish SSH connections with all remote servers.")
 
-	d.executor = operation.NewGeminiExecutor(d.sshClients)
-	return nil
-}
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
 
-func (d *GeminiStatusPatroller) prepareRemotes(c *config.Config) error {
-	if c == nil {
-		return utils.ErrUnexpectedNil
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return err
 	}
-
-	for ip, ssh := range c.SSHConfig {
-		d.remotes[ip] = &config.RemoteHost{
-			Ip:         ip,
-			SSHPort:    ssh.Port,
-			UpDataPath: ssh.UpDataPath,
-			LogPath:    ssh.LogPath,
-			User:       d.clusterOptions.User,
-			Typ:        d.clusterOptions.SshType,
-			Password:   d.clusterOptions.Password,
-			KeyPath:    d.clusterOptions.Key,
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := b.Build().Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			return err
 		}
+		return errors.WithStack(err)
 	}
 
-	if err := d.tryConnect(); err != nil {
-		return err
+	comps := topo.ComponentsByStartOrder()
+	if s, ok := topo.(*spec.Specification); ok {
+		comps = append(comps, &spec.TSMonitorComponent{Topology: s})
+	}
+	var insts []spec.Instance
+	for _, comp := range operation.FilterComponent(comps, set.NewStringSet(gOpt.Roles...)) {
+		insts = append(insts, operation.FilterInstance(comp.Instances(), set.NewStringSet(gOpt.Nodes...))...)
 	}
 
-	return nil
-}
-
-func (d *GeminiStatusPatroller) tryConnect() error {
-	for ip, r := range d.remotes {
-		var err error
-		var sshClient *ssh.Client
-		switch r.Typ {
-		case utils.SSH_PW:
-			sshClient, err = utils.NewSSH_PW(r.User, r.Password, r.Ip, r.SSHPort)
-		case utils.SSH_KEY:
-			sshClient, err = utils.NewSSH_Key(r.User, r.KeyPath, r.Ip, r.SSHPort)
+	statuses := make([]*InstanceStatus, len(insts))
+	timeout := time.Duration(gOpt.APITimeout) * time.Second
+	wg := sync.WaitGroup{}
+	for idx, ins := range insts {
+		wg.Add(1)
+		go func(idx int, ins spec.Instance) {
+			defer wg.Done()
+			statuses[idx] = instanceStatus(ctx, ins, timeout)
+		}(idx, ins)
+	}
+	wg.Wait()
 
-		}
+	switch gOpt.DisplayMode {
+	case "json":
+		d, err := json.MarshalIndent(struct {
+			Cluster   string            `json:"cluster"`
+			Version   string            `json:"version"`
+			Instances []*InstanceStatus `json:"instances"`
+		}{name, base.Version, statuses}, "", "  ")
 		if err != nil {
-			return err
+			return errors.WithStack(err)
 		}
-		d.sshClients[ip] = sshClient
-	}
-	return nil
-}
-
-func (d *GeminiStatusPatroller) Patrol() error {
-	statusChan := make(chan ClusterStatusPerServer, len(d.remotes))
-	errChan := make(chan error, len(d.remotes))
-	var wgp sync.WaitGroup
-	wgp.Add(3)
-	go func() {
-		defer wgp.Done()
-		d.wg.Add(len(d.remotes))
-		for ip := range d.remotes {
-			go d.patrolOneServer(ip, statusChan, errChan)
+		fmt.Println(string(d))
+	default:
+		fmt.Printf("Cluster name:       %s\n", color.CyanString(name))
+		fmt.Printf("Cluster version:    %s\n", color.CyanString(base.Version))
+		statusTable := [][]string{
+			// Header
+			{"ID", "Role", "Host", "Service", "Ports", "Data Dir", "Disk Usage"},
 		}
-		d.wg.Wait()
-		close(errChan)
-		close(statusChan)
-	}()
-
-	var has_err = false
-	go func() {
-		defer wgp.Done()
-		for {
-			err, ok := <-errChan
-			if !ok {
-				return
-			}
-			fmt.Println(err)
-			has_err = true
+		for _, s := range statuses {
+			statusTable = append(statusTable, []string{
+				s.ID,
+				s.Role,
+				s.Host,
+				formatServiceActive(s.Active),
+				formatPortStatus(s.Ports),
+				orDash(s.DataDir),
+				formatDiskUsage(s.Disks),
+			})
 		}
-	}()
+		gui.PrintTable(statusTable, true)
 
-	go func() {
-		defer wgp.Done()
-		for {
-			status, ok := <-statusChan
-			if !ok {
-				return
+		for _, s := range statuses {
+			for _, e := range s.Errors {
+				m.logger.Warnf("%s: %s", s.ID, e)
 			}
-			displayGeminiStatus(status)
 		}
-	}()
-
-	wgp.Wait()
-	if has_err {
-		return errors.New("check cluster status failed")
 	}
 	return nil
 }
 
-func (d *GeminiStatusPatroller) patrolOneServer(ip string, statusChan chan ClusterStatusPerServer, errChan chan error) {
-	defer d.wg.Done()
-	var status = ClusterStatusPerServer{
-		Ip:            ip,
-		PortOccupancy: make(map[int]bool),
-	}
-	var err error
-
-	// check running process
-	output, err := d.executor.ExecCommand(ip, CheckProcessCommand)
+// instanceStatus collects the status of the instance through the executor of its host
+func instanceStatus(ctx context.Context, ins spec.Instance, timeout time.Duration) *InstanceStatus {
+	status := &InstanceStatus{
+		ID:      ins.ID(),
+		Role:    ins.ComponentName(),
+		Host:    ins.GetHost(),
+		Service: ins.ServiceName(),
+		DataDir: ins.DataDir(),
+	}
+	e, ok := ctxt.GetInner(ctx).GetExecutor(ins.GetManageHost())
+	if !ok {
+		status.Active = "unknown"
+		status.Errors = append(status.Errors, "no executor for the host")
+		return status
+	}
+
+	// `systemctl is-active` exits with non-zero code if the unit is not active
+	systemd := module.NewSystemdModule(module.SystemdModuleConfig{
+		Unit:    ins.ServiceName(),
+		Action:  "is-active",
+		Timeout: timeout,
+	})
+	stdout, _, _ := systemd.Execute(ctx, e)
+	status.Active = strings.TrimSpace(string(stdout))
+	if status.Active == "" {
+		status.Active = "unknown"
+	}
+
+	// the ports are checked in the same way as module.WaitFor, but only once
+	stdout, stderr, err := e.Execute(ctx, "ss -ltn", false)
 	if err != nil {
-		fmt.Println(err)
-		errChan <- err
-		return
-	} else {
-		status.RunningProcesses = strings.Split(output, "\n")
+		status.Errors = append(status.Errors, fmt.Sprintf("failed to list the ports: %s", strings.TrimSpace(string(stderr))))
 	}
-
-	// check port status about ts-meta
-	for _, i := range d.conf.CommonConfig.MetaHosts {
-		if ip == i {
-			tomlPath := filepath.Join(utils.DownloadDst, d.version, utils.LocalEtcRelPath, ip, utils.RemoteMetaConfName)
-			t, err := config.ReadFromToml(tomlPath)
-			if err != nil {
-				fmt.Println(err)
-				errChan <- err
-				return
-			}
-
-			if occupied, port, err := d.checkOnePortWithStr(ip, t.Meta.BindAddress); err != nil {
-				fmt.Println(err)
-				errChan <- err
-				return
-			} else {
-				status.PortOccupancy[port] = occupied
-			}
-
-			if occupied, port, err := d.checkOnePortWithStr(ip, t.Meta.HttpBindAddress); err != nil {
-				fmt.Println(err)
-				errChan <- err
-				return
-			} else {
-				status.PortOccupancy[port] = occupied
-			}
-
-			if occupied, port, err := d.checkOnePortWithStr(ip, t.Meta.RpcBindAddress); err != nil {
-				fmt.Println(err)
-				errChan <- err
-				return
-			} else {
-				status.PortOccupancy[port] = occupied
-			}
-
-			if occupied, port, err := d.checkOnePortWithInt(ip, t.Gossip.MetaBindPort); err != nil {
-				fmt.Println(err)
-				errChan <- err
-				return
-			} else {
-				status.PortOccupancy[port] = occupied
-			}
-		}
+	for _, port := range ins.UsedPorts() {
+		status.Ports = append(status.Ports, PortStatus{
+			Port:      port,
+			Listening: err == nil && bytes.Contains(stdout, []byte(fmt.Sprintf(":%d ", port))),
+		})
 	}
-	// check port status about ts-sql
-	for _, i := range d.conf.CommonConfig.SqlHosts {
-		if ip == i {
-			tomlPath := filepath.Join(utils.DownloadDst, d.version, utils.LocalEtcRelPath, ip, utils.RemoteSqlConfName)
-			t, err := config.ReadFromToml(tomlPath)
-			if err != nil {
-				fmt.Println(err)
-				errChan <- err
-				return
-			}
 
-			if occupied, port, err := d.checkOnePortWithStr(ip, t.Http.BindAddress); err != nil {
-				fmt.Println(err)
-				errChan <- err
-				return
-			} else {
-				status.PortOccupancy[port] = occupied
-			}
-		}
+	if ins.DataDir() == "" {
+		return status
 	}
-	// check port status about ts-store
-	for _, i := range d.conf.CommonConfig.StoreHosts {
-		if ip == i {
-			tomlPath := filepath.Join(utils.DownloadDst, d.version, utils.LocalEtcRelPath, ip, utils.RemoteStoreConfName)
-			t, err := config.ReadFromToml(tomlPath)
-			if err != nil {
-				fmt.Println(err)
-				errChan <- err
-				return
-			}
-
-			if occupied, port, err := d.checkOnePortWithStr(ip, t.Data.StoreIngestAddr); err != nil {
-				fmt.Println(err)
-				errChan <- err
-				return
-			} else {
-				status.PortOccupancy[port] = occupied
-			}
-
-			if occupied, port, err := d.checkOnePortWithStr(ip, t.Data.StoreSelectAddr); err != nil {
-				fmt.Println(err)
-				errChan <- err
-				return
-			} else {
-				status.PortOccupancy[port] = occupied
-			}
-
-			if occupied, port, err := d.checkOnePortWithInt(ip, t.Gossip.StoreBindPort); err != nil {
-				fmt.Println(err)
-				errChan <- err
-				return
-			} else {
-				status.PortOccupancy[port] = occupied
-			}
+	for _, dir := range strings.Split(ins.DataDir(), ",") {
+		usage, err := diskUsage(ctx, e, dir)
+		if err != nil {
+			status.Errors = append(status.Errors, err.Error())
+			continue
 		}
+		status.Disks = append(status.Disks, *usage)
 	}
-
-	// check disk capacity
-	output, err = d.executor.ExecCommand(ip, CheckDiskCapacityCommand)
-	if err != nil {
-		fmt.Println(err)
-		errChan <- err
-		return
-	} else {
-		status.DiskCapacity = strings.Split(output, "\n")
-	}
-	statusChan <- status
+	return status
 }
 
-func (d *GeminiStatusPatroller) checkOnePortWithStr(ip, inputStr string) (bool, int, error) {
-	parts := strings.Split(inputStr, ":")
-	if len(parts) < 2 {
-		return true, 0, errors.New("invalid inputStr format when check one port status")
-	}
-	portStr := parts[1]
-
-	port, err := strconv.Atoi(portStr)
+// diskUsage queries the usage of the filesystem where the dir is
+func diskUsage(ctx context.Context, e ctxt.Executor, dir string) (*operation.DiskUsage, error) {
+	stdout, stderr, err := e.Execute(ctx, fmt.Sprintf("df -Pk %s", dir), false)
 	if err != nil {
-		return true, port, err
+		return nil, errors.Errorf("failed to get the disk usage of %s: %s", dir, strings.TrimSpace(string(stderr)))
 	}
+	return operation.ParseDiskUsage(dir, string(stdout))
+}
 
-	output, err := d.executor.ExecCommand(ip, GenCheckPortCommand(port))
-	if err != nil {
-		return true, port, err
-	} else {
-		if strings.Contains(output, "yes") {
-			return true, port, nil
-		} else if strings.Contains(output, "no") {
-			return false, port, nil
-		}
+func formatServiceActive(active string) string {
+	switch active {
+	case "active":
+		return color.GreenString(active)
+	case "inactive", "failed":
+		return color.RedString(active)
+	default:
+		return active
 	}
-	return true, port, errors.New("unexpected port status result")
 }
 
-func (d *GeminiStatusPatroller) checkOnePortWithInt(ip string, port int) (bool, int, error) {
-	output, err := d.executor.ExecCommand(ip, GenCheckPortCommand(port))
-	if err != nil {
-		return true, port, err
-	} else {
-		if strings.Contains(output, "yes") {
-			return true, port, nil
-		} else if strings.Contains(output, "no") {
-			return false, port, nil
+func formatPortStatus(ports []PortStatus) string {
+	var s []string
+	for _, p := range ports {
+		if p.Listening {
+			s = append(s, color.GreenString("%d", p.Port))
+		} else {
+			s = append(s, color.RedString("%d", p.Port))
 		}
 	}
-	return true, port, errors.New("unexpected port status result")
+	return orDash(strings.Join(s, "/"))
 }
 
-func (d *GeminiStatusPatroller) Close() {
-	var err error
-	for _, ssh := range d.sshClients {
-		if err = ssh.Close(); err != nil {
-			fmt.Println(err)
+func formatDiskUsage(disks []operation.DiskUsage) string {
+	var s []string
+	for _, d := range disks {
+		if d.Total == 0 {
+			continue
 		}
+		s = append(s, fmt.Sprintf("%s/%s (%d%%)",
+			operation.ReadableKB(d.Used), operation.ReadableKB(d.Total), d.Used*100/d.Total))
 	}
+	return orDash(strings.Join(s, ","))
 }
 
-func displayGeminiStatus(status ClusterStatusPerServer) {
-	fmt.Printf("\nGemini status of server %s\n", status.Ip)
-
-	// Create a new table for Running Processes
-	runningProcessesTable := tablewriter.NewWriter(os.Stdout)
-	runningProcessesTable.SetHeader([]string{"Running Processes"})
-	for _, process := range status.RunningProcesses {
-		runningProcessesTable.Append([]string{fmt.Sprintf("%v", process)})
-	}
-	runningProcessesTable.Render()
-
-	// Create a new table for Port Occupancy
-	portOccupancyTable := tablewriter.NewWriter(os.Stdout)
-	portOccupancyTable.SetHeader([]string{"Port Occupancy"})
-	for port, occupied := range status.PortOccupancy {
-		portOccupancyTable.Append([]string{fmt.Sprintf("Port %d: Occupied: %v", port, occupied)})
-	}
-	portOccupancyTable.Render()
-
-	// Create a new table for Disk Capacity
-	diskCapacityTable := tablewriter.NewWriter(os.Stdout)
-	diskCapacityTable.SetHeader([]string{"Disk Capacity"})
-	for _, capacity := range status.DiskCapacity {
-		diskCapacityTable.Append([]string{fmt.Sprintf("%v", capacity)})
+// orDash returns "-" for the empty string
+func orDash(s string) string {
+	if s == "" {
+		return "-"
 	}
-	diskCapacityTable.Render()
+	return s
 }
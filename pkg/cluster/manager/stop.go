@@ -45,11 +45,8 @@ func (m *Manager) StopCluster(
 
 	topo := metadata.GetTopology()
 	base := metadata.GetBaseMeta()
-	//
-	//tlsCfg, err := topo.TLSConfig(m.specManager.Path(name, spec.TLSCertKeyDir))
-	//if err != nil {
-	//	return err
-	//}
+	// unlike start, stopping needs no TLS config: the instances are stopped by
+	// systemctl which waits for them to exit, no readiness api is requested
 
 	if !skipConfirm {
 		if err = gui.PromptForConfirmOrAbortError(
@@ -0,0 +1,457 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	operator "github.com/openGemini/gemix/pkg/cluster/operation"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/cluster/task"
+	"github.com/openGemini/gemix/pkg/crypto"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/set"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// genAndSaveCertificate generates the CA and the client certificate of the TLS enabled
+// cluster and saves them in the profile dir of the cluster, nil is returned if TLS is disabled.
+func (m *Manager) genAndSaveCertificate(clusterName string, globalOptions *spec.GlobalOptions) (*crypto.CertificateAuthority, error) {
+	if !globalOptions.TLSEnabled {
+		return nil, nil
+	}
+
+	ca, err := genAndSaveClusterCA(clusterName, m.specManager.Path(clusterName, spec.TLSCertKeyDir))
+	if err != nil {
+		return nil, err
+	}
+	if err = genAndSaveClientCert(ca, clusterName, m.specManager.Path(clusterName, spec.TLSCertKeyDir)); err != nil {
+		return nil, err
+	}
+	return ca, nil
+}
+
+// genAndSaveClusterCA generates a new CA and saves it to the tls dir, the trusted
+// certificates are appended to the CA certificate file to be trusted as well.
+func genAndSaveClusterCA(clusterName, tlsPath string, trusted ...*x509.Certificate) (*crypto.CertificateAuthority, error) {
+	ca, err := crypto.NewCA(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.MkdirAll(tlsPath, 0755); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := os.WriteFile(filepath.Join(tlsPath, spec.TLSCAKey), ca.Key.Pem(), 0600); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := saveCACert(tlsPath, append([]*x509.Certificate{ca.Cert}, trusted...)...); err != nil {
+		return nil, err
+	}
+	return ca, nil
+}
+
+// saveCACert saves the CA certificates to the tls dir, the first one is the
+// current CA and the others are only trusted.
+func saveCACert(tlsPath string, certs ...*x509.Certificate) error {
+	var data []byte
+	for _, cert := range certs {
+		data = append(data, pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: cert.Raw,
+		})...)
+	}
+	return errors.WithStack(os.WriteFile(filepath.Join(tlsPath, spec.TLSCACert), data, 0644))
+}
+
+// readCACerts reads all the certificates in the CA certificate file of the tls dir
+func readCACerts(tlsPath string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(filepath.Join(tlsPath, spec.TLSCACert))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to parse the CA certificate")
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// genAndSaveClientCert generates the client certificate used by gemix and saves it to the tls dir
+func genAndSaveClientCert(ca *crypto.CertificateAuthority, clusterName, tlsPath string) error {
+	privKey, err := crypto.NewKeyPair(crypto.KeyTypeRSA, crypto.KeySchemeRSASSAPSSSHA256)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	csr, err := privKey.CSR("gemix", clusterName, []string{}, []string{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	certBytes, err := ca.Sign(csr)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(tlsPath, spec.TLSClientKey), privKey.Pem(), 0600); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.WriteFile(filepath.Join(tlsPath, spec.TLSClientCert), pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: certBytes,
+	}), 0644); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// readClusterCA reads the CA of the cluster saved in the profile dir
+func (m *Manager) readClusterCA(clusterName string) (*crypto.CertificateAuthority, error) {
+	return crypto.ReadCA(
+		clusterName,
+		m.specManager.Path(clusterName, spec.TLSCertKeyDir, spec.TLSCACert),
+		m.specManager.Path(clusterName, spec.TLSCertKeyDir, spec.TLSCAKey),
+	)
+}
+
+// tlsComponents are the components with certificates in the TLS enabled cluster,
+// the monitoring components are kept serving plain http.
+var tlsComponents = []string{spec.ComponentTSMeta, spec.ComponentTSStore, spec.ComponentTSSql}
+
+// RotateTLS re-issues the certificates of the instances and restarts them one by one,
+// a new CA is generated if rotateCA is set. The previous CA is trusted until the next
+// rotation, so that the instances with the certificates of both CAs can communicate
+// with each other during the rolling restart. The CAs trusted before are kept until the
+// rotation completes, as they may still be in use if the last rotation failed.
+func (m *Manager) RotateTLS(name string, rotateCA bool, gOpt operator.Options, skipConfirm bool) error {
+	if gOpt.DryRun {
+		return errors.New("tls rotation does not support --dry-run, the certificates of the cluster are re-issued locally")
+	}
+	// check locked
+	if err := m.specManager.ScaleOutLockedErr(name); err != nil {
+		return err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+	if !topo.BaseTopo().GlobalOptions.TLSEnabled {
+		return errors.Errorf("TLS is not enabled in cluster `%s`", name)
+	}
+
+	tlsPath := m.specManager.Path(name, spec.TLSCertKeyDir)
+	oldCA, err := m.readClusterCA(name)
+	if err != nil {
+		return err
+	}
+	// the certificates still trusted if the last rotation failed
+	trusted, err := readCACerts(tlsPath)
+	if err != nil {
+		return err
+	}
+	if !skipConfirm {
+		target := "the certificates of the instances"
+		if rotateCA {
+			target = "the CA and the certificates of the instances"
+		}
+		if err := gui.PromptForConfirmOrAbortError(
+			"This operation will re-issue %s of cluster %s and restart the instances one by one.\nDo you want to continue? [y/N]:",
+			color.HiYellowString(target),
+			color.HiYellowString(name)); err != nil {
+			return err
+		}
+	}
+
+	restartOpt := gOpt
+	restartOpt.Roles = tlsComponents
+	restartOpt.Nodes = nil
+	rollingRestart := func(ctx context.Context) error {
+		// the client certificate may be changed, so the config is loaded before restarting
+		tlsCfg, err := topo.TLSConfig(tlsPath)
+		if err != nil {
+			return err
+		}
+		return operator.RollingRestart(ctx, topo, restartOpt, tlsCfg)
+	}
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return err
+	}
+
+	if rotateCA {
+		// trust the new CA on all the instances before the certificates are replaced
+		if _, err := genAndSaveClusterCA(name, tlsPath, trusted...); err != nil {
+			return err
+		}
+		b.ParallelStep("+ Copy CA certificate to remote host", false, buildCACertTasks(m, name, topo, base)...).
+			Func("RollingRestart", rollingRestart)
+	}
+	if err := m.executeTLSTask(ctx, b.Build()); err != nil {
+		return err
+	}
+
+	ca, err := m.readClusterCA(name)
+	if err != nil {
+		return err
+	}
+	if err := genAndSaveClientCert(ca, name, tlsPath); err != nil {
+		return err
+	}
+	certificateTasks, err := buildCertificateTasks(m, name, topo, base, gOpt, nil)
+	if err != nil {
+		return err
+	}
+	t := task.NewBuilder(m.logger).
+		ParallelStep("+ Copy certificate to remote host", false, certificateTasks...).
+		Func("RollingRestart", rollingRestart).
+		Build()
+	if err := m.executeTLSTask(ctx, t); err != nil {
+		return err
+	}
+
+	// all the instances use the certificates of the current CA now, only the previous
+	// CA is kept trusted until the next rotation, the older ones are dropped
+	keep := []*x509.Certificate{ca.Cert}
+	if rotateCA {
+		keep = append(keep, oldCA.Cert)
+	}
+	if err := saveCACert(tlsPath, keep...); err != nil {
+		return err
+	}
+
+	m.logger.Infof("Rotated the certificates of cluster `%s` successfully", name)
+	return nil
+}
+
+// executeTLSTask executes the task of tls rotation
+func (m *Manager) executeTLSTask(ctx context.Context, t task.Task) error {
+	if err := t.Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return err
+		}
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// buildCACertTasks transfers the CA certificate of the cluster to the instances
+func buildCACertTasks(m *Manager, clusterName string, topo spec.Topology, base *spec.BaseMeta) []*task.StepDisplay {
+	caCert := m.specManager.Path(clusterName, spec.TLSCertKeyDir, spec.TLSCACert)
+	roleFilter := set.NewStringSet(tlsComponents...)
+
+	var tasks []*task.StepDisplay
+	topo.IterInstance(func(inst spec.Instance) {
+		if !roleFilter.Exist(inst.ComponentName()) {
+			return
+		}
+		host := inst.GetManageHost()
+		dst := spec.TLSCAPath(spec.Abs(base.User, inst.DeployDir()))
+		tb := task.NewBuilder(m.logger).
+			Func("CopyCACert", func(ctx context.Context) error {
+				e, ok := ctxt.GetInner(ctx).GetExecutor(host)
+				if !ok {
+					return task.ErrNoExecutor
+				}
+				return e.Transfer(ctx, caCert, dst, false, 0, false)
+			})
+		tasks = append(tasks, tb.BuildAsStep(fmt.Sprintf("  - Copy CA certificate %s -> %s", inst.ComponentName(), inst.ID())))
+	})
+	return tasks
+}
+
+// CertInfo is the validity of a certificate of the cluster
+type CertInfo struct {
+	ID        string    `json:"id"`
+	Role      string    `json:"role"`
+	Host      string    `json:"host"`
+	Subject   string    `json:"subject"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// ShowTLS prints the expiry dates of the CA, the client certificate and the
+// certificates of the instances.
+func (m *Manager) ShowTLS(name string, gOpt operator.Options) error {
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+	if !topo.BaseTopo().GlobalOptions.TLSEnabled {
+		return errors.Errorf("TLS is not enabled in cluster `%s`", name)
+	}
+
+	var certs []*CertInfo
+	for _, local := range []struct{ role, file string }{
+		{"ca", spec.TLSCACert},
+		{"client", spec.TLSClientCert},
+	} {
+		info := &CertInfo{ID: "-", Role: local.role, Host: "-"}
+		data, err := os.ReadFile(m.specManager.Path(name, spec.TLSCertKeyDir, local.file))
+		if err != nil {
+			info.Error = err.Error()
+		} else {
+			info.fill(data)
+		}
+		certs = append(certs, info)
+	}
+
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return err
+	}
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := m.executeTLSTask(ctx, b.Build()); err != nil {
+		return err
+	}
+
+	var insts []spec.Instance
+	for _, comp := range operator.FilterComponent(topo.ComponentsByStartOrder(), set.NewStringSet(tlsComponents...)) {
+		insts = append(insts, comp.Instances()...)
+	}
+	instCerts := make([]*CertInfo, len(insts))
+	wg := sync.WaitGroup{}
+	for idx, inst := range insts {
+		wg.Add(1)
+		go func(idx int, inst spec.Instance) {
+			defer wg.Done()
+			info := &CertInfo{ID: inst.ID(), Role: inst.ComponentName(), Host: inst.GetHost()}
+			instCerts[idx] = info
+
+			e := ctxt.GetInner(ctx).Get(inst.GetManageHost())
+			certPath := spec.TLSCertPath(spec.Abs(base.User, inst.DeployDir()), inst.ComponentName())
+			stdout, stderr, err := e.Execute(ctx, fmt.Sprintf("cat %s", certPath), false)
+			if err != nil {
+				info.Error = strings.TrimSpace(string(stderr))
+				if info.Error == "" {
+					info.Error = err.Error()
+				}
+				return
+			}
+			info.fill(stdout)
+		}(idx, inst)
+	}
+	wg.Wait()
+	certs = append(certs, instCerts...)
+
+	switch gOpt.DisplayMode {
+	case "json":
+		d, err := json.MarshalIndent(struct {
+			Certificates []*CertInfo `json:"certificates"`
+		}{certs}, "", "  ")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fmt.Println(string(d))
+	default:
+		fmt.Printf("Cluster name:       %s\n", color.CyanString(name))
+		certTable := [][]string{
+			// Header
+			{"ID", "Role", "Host", "Not Before", "Not After", "Expires In"},
+		}
+		for _, c := range certs {
+			if c.Error != "" {
+				certTable = append(certTable, []string{c.ID, c.Role, c.Host, "-", "-", color.RedString(c.Error)})
+				continue
+			}
+			certTable = append(certTable, []string{
+				c.ID,
+				c.Role,
+				c.Host,
+				c.NotBefore.Local().Format(time.RFC3339),
+				c.NotAfter.Local().Format(time.RFC3339),
+				formatCertExpiry(c.NotAfter),
+			})
+		}
+		gui.PrintTable(certTable, true)
+	}
+	return nil
+}
+
+// fill parses the first certificate in the PEM data
+func (c *CertInfo) fill(data []byte) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		c.Error = "invalid certificate"
+		return
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		c.Error = err.Error()
+		return
+	}
+	c.Subject = cert.Subject.String()
+	c.NotBefore = cert.NotBefore
+	c.NotAfter = cert.NotAfter
+}
+
+// certExpiryWarningDays is the days before expiry to highlight the certificate
+const certExpiryWarningDays = 30
+
+// formatCertExpiry returns the days before the certificate expires
+func formatCertExpiry(notAfter time.Time) string {
+	left := time.Until(notAfter)
+	if left <= 0 {
+		return color.RedString("expired")
+	}
+	days := int(left.Hours() / 24)
+	s := fmt.Sprintf("%d days", days)
+	if days < certExpiryWarningDays {
+		return color.YellowString(s)
+	}
+	return color.GreenString(s)
+}
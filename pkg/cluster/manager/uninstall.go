@@ -79,6 +79,10 @@ func (m *Manager) UninstallCluster(name string, gOpt operator.Options, destroyOp
 		return errors.WithStack(err)
 	}
 
+	if gOpt.DryRun {
+		return nil
+	}
+
 	if err = m.specManager.Remove(name); err != nil {
 		return errors.WithStack(err)
 	}
@@ -0,0 +1,204 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/joomcode/errorx"
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	operator "github.com/openGemini/gemix/pkg/cluster/operation"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/cluster/task"
+	"github.com/openGemini/gemix/pkg/gui"
+	"github.com/openGemini/gemix/pkg/set"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// Upgrade the cluster to the target version in place, the binaries are replaced and
+// the instances are restarted one by one, the metadata is updated only on success.
+func (m *Manager) Upgrade(name string, clusterVersion string, gOpt operator.Options, skipConfirm bool) error {
+	// check locked
+	if err := m.specManager.ScaleOutLockedErr(name); err != nil {
+		return err
+	}
+
+	metadata, err := m.meta(name)
+	if err != nil {
+		return err
+	}
+
+	topo := metadata.GetTopology()
+	base := metadata.GetBaseMeta()
+
+	tlsCfg, err := topo.TLSConfig(m.specManager.Path(name, spec.TLSCertKeyDir))
+	if err != nil {
+		return err
+	}
+
+	if clusterVersion, err = utils.FmtVer(clusterVersion); err != nil {
+		return err
+	}
+	if clusterVersion == base.Version {
+		return errors.Errorf("cluster `%s` is already at version %s", name, clusterVersion)
+	}
+
+	// the patched binaries are replaced by the ones of the target version
+	var patched []string
+	topo.IterInstance(func(inst spec.Instance) {
+		if inst.IsPatched() {
+			patched = append(patched, inst.ID())
+		}
+	})
+	if len(patched) > 0 {
+		m.logger.Warnf("%s", color.YellowString("The instances %s are patched, the patched binaries will be overwritten by the upgrade",
+			strings.Join(patched, ",")))
+	}
+
+	if !skipConfirm {
+		if err := gui.PromptForConfirmOrAbortError(
+			"This operation will upgrade %s cluster %s from %s to %s.\nDo you want to continue? [y/N]:",
+			m.sysName,
+			color.HiYellowString(name),
+			color.HiYellowString(base.Version),
+			color.HiYellowString(clusterVersion)); err != nil {
+			return err
+		}
+		m.logger.Infof("Upgrading cluster...")
+	}
+
+	downloadCompTasks := buildDownloadCompTasks(clusterVersion, topo, m.logger)
+
+	newBase := &spec.BaseMeta{
+		User:    base.User,
+		Group:   base.Group,
+		Version: clusterVersion,
+	}
+
+	// the binaries and configs of an instance are backed up and replaced right before it
+	// is restarted, so the instances not upgraded yet are kept intact if any upgrade fails
+	upgradeTasks := make(map[string]task.Task)
+	var refreshConfigTasks []*task.StepDisplay
+	topo.IterInstance(func(inst spec.Instance) {
+		if inst.ComponentSource() != spec.ComponentOpenGemini {
+			refreshConfigTasks = append(refreshConfigTasks, buildInitConfigTask(m, name, inst, newBase, gOpt, false))
+			return
+		}
+		deployDir := spec.Abs(base.User, inst.DeployDir())
+		upgradeTasks[inst.ID()] = task.NewBuilder(m.logger).
+			BackupComponent(inst.ComponentName(), base.Version, inst.GetManageHost(), deployDir).
+			CopyComponent(
+				inst.ComponentSource(),
+				inst.ComponentName(),
+				inst.OS(),
+				inst.Arch(),
+				clusterVersion,
+				"", // use default srcPath
+				inst.GetManageHost(),
+				deployDir,
+			).
+			InitConfig(
+				name,
+				clusterVersion,
+				m.specManager,
+				inst,
+				base.User,
+				gOpt.IgnoreConfigCheck,
+				instanceDirPaths(m, name, inst, newBase),
+				false,
+			).
+			Build()
+	})
+
+	// ts-monitor is upgraded after all the instances
+	var monitorHosts []string
+	var copyMonitorTasks []*task.StepDisplay
+	monitoredOptions := topo.GetMonitoredOptions()
+	if monitoredOptions != nil && monitoredOptions.TSMonitorEnabled {
+		uniqueHosts, noAgentHosts := getMonitorHosts(topo)
+		deployDir := spec.Abs(base.User, monitoredOptions.DeployDir)
+		for host, info := range uniqueHosts {
+			if noAgentHosts.Exist(host) {
+				continue
+			}
+			monitorHosts = append(monitorHosts, host)
+			copyMonitorTasks = append(copyMonitorTasks, task.NewBuilder(m.logger).
+				BackupComponent(spec.ComponentTSMonitor, base.Version, host, deployDir).
+				CopyComponent(
+					spec.ComponentOpenGemini,
+					spec.ComponentTSMonitor,
+					info.Os,
+					info.Arch,
+					clusterVersion,
+					"", // use default srcPath
+					host,
+					deployDir,
+				).
+				BuildAsStep(fmt.Sprintf("  - Backup and copy %s -> %s", spec.ComponentTSMonitor, host)))
+		}
+	}
+
+	b, err := m.sshTaskBuilder(name, topo, base.User, gOpt)
+	if err != nil {
+		return err
+	}
+	b.ParallelStep("+ Download openGemini components", false, downloadCompTasks...).
+		ParallelStep("+ Refresh instance configs", false, refreshConfigTasks...).
+		Func("UpgradeCluster", func(ctx context.Context) error {
+			return operator.Upgrade(ctx, topo, gOpt, tlsCfg, func(ctx context.Context, ins spec.Instance) error {
+				return upgradeTasks[ins.ID()].Execute(ctx)
+			})
+		})
+	if len(monitorHosts) > 0 {
+		b.ParallelStep("+ Backup and copy ts-monitor", false, copyMonitorTasks...).
+			Func("RestartMonitored", func(ctx context.Context) error {
+				return operator.RestartMonitored(ctx, monitorHosts, set.NewStringSet(), gOpt.OptTimeout)
+			})
+	}
+	t := b.Build()
+
+	ctx := ctxt.New(
+		context.Background(),
+		gOpt.Concurrency,
+		m.logger,
+	)
+	if err := t.Execute(ctx); err != nil {
+		if errorx.Cast(err) != nil {
+			// FIXME: Map possible task errors and give suggestions.
+			return err
+		}
+		return errors.WithStack(err)
+	}
+
+	if gOpt.DryRun {
+		return nil
+	}
+
+	topo.IterInstance(func(inst spec.Instance) {
+		inst.SetPatched(false)
+	})
+	clusterMeta := metadata.(*spec.ClusterMeta)
+	clusterMeta.SetVersion(clusterVersion)
+	if err := m.specManager.SaveMeta(name, clusterMeta); err != nil {
+		return err
+	}
+
+	m.logger.Infof("Upgraded cluster `%s` successfully", name)
+	return nil
+}
@@ -14,7 +14,6 @@
 package module
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"time"
@@ -63,28 +62,22 @@ func NewWaitFor(c WaitForConfig) *WaitFor {
 
 // Execute the module return nil if successfully wait for the event.
 func (w *WaitFor) Execute(ctx context.Context, e ctxt.Executor) (err error) {
-	pattern := []byte(fmt.Sprintf(":%d ", w.c.Port))
+	// the state is told by the exit status of the command, only listing TCP ports
+	cmd := fmt.Sprintf("ss -ltn | grep -q ':%d '", w.c.Port)
+	switch w.c.State {
+	case "started":
+	case "stopped":
+		cmd = "! " + cmd
+	default:
+		return errors.Errorf("unknown state %s to wait for port %d", w.c.State, w.c.Port)
+	}
 
 	retryOpt := utils.RetryOption{
 		Delay:   w.c.Sleep,
 		Timeout: w.c.Timeout,
 	}
 	if err := utils.Retry(func() error {
-		// only listing TCP ports
-		stdout, _, err := e.Execute(ctx, "ss -ltn", false)
-		if err == nil {
-			switch w.c.State {
-			case "started":
-				if bytes.Contains(stdout, pattern) {
-					return nil
-				}
-			case "stopped":
-				if !bytes.Contains(stdout, pattern) {
-					return nil
-				}
-			}
-			return errors.New("still waiting for port state to be satisfied")
-		}
+		_, _, err := e.Execute(ctx, cmd, false)
 		return err
 	}, retryOpt); err != nil {
 		zap.L().Debug("retry error", zap.Error(err))
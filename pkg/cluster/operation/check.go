@@ -0,0 +1,498 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operation
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	logprinter "github.com/openGemini/gemix/pkg/logger/printer"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
+)
+
+// names of the check items
+const (
+	CheckNameOSArch      = "os-arch"
+	CheckNameDisk        = "disk"
+	CheckNameMem         = "memory"
+	CheckNameCPU         = "cpu-cores"
+	CheckNameLimits      = "limits"
+	CheckNameSysctl      = "sysctl"
+	CheckNameSwap        = "swap"
+	CheckNameTHP         = "thp"
+	CheckNameTimeSync    = "time-sync"
+	CheckNamePorts       = "listening-port"
+	CheckNameGlibc       = "glibc"
+	CheckNameSystemd     = "systemd"
+	CheckStatusPass      = "Pass"
+	CheckStatusWarn      = "Warn"
+	CheckStatusFail      = "Fail"
+	CheckStatusFixed     = "Fixed"
+	checkMinCPUCores     = 4
+	checkMinMemKB        = 8 * 1024 * 1024
+	checkMinDiskFreeKB   = 10 * 1024 * 1024
+	checkFatalDiskFreeKB = 1024 * 1024
+	checkMinOpenFiles    = 1000000
+	checkMinGlibc        = "v2.17"
+	thpEnabledPath       = "/sys/kernel/mm/transparent_hugepage/enabled"
+	thpDefragPath        = "/sys/kernel/mm/transparent_hugepage/defrag"
+	sysctlConfPath       = "/etc/sysctl.d/99-gemix.conf"
+)
+
+// sysctlParams are the kernel parameters recommended for openGemini,
+// the value of vm.swappiness is the expected one, the others are the minimal ones
+var sysctlParams = []struct {
+	key   string
+	value int
+}{
+	{"vm.swappiness", 0},
+	{"fs.file-max", 1000000},
+	{"net.core.somaxconn", 32768},
+}
+
+// CheckResult is the result of a check item on a host
+type CheckResult struct {
+	Name  string // name of the check item
+	Err   error  // the reason of the failure, nil if the check is passed
+	Warn  bool   // the failure is not fatal to deploy the cluster
+	Fixed bool   // the failure has been fixed by the auto-fix
+	Msg   string // detail of the result
+
+	fix string // the command to fix the failure, set by the items depending on the current state
+}
+
+// Passed returns true if the check item is passed
+func (r *CheckResult) Passed() bool {
+	return r.Err == nil
+}
+
+// Status returns the status of the check item: Pass, Warn, Fail or Fixed
+func (r *CheckResult) Status() string {
+	switch {
+	case r.Passed():
+		return CheckStatusPass
+	case r.Fixed:
+		return CheckStatusFixed
+	case r.Warn:
+		return CheckStatusWarn
+	default:
+		return CheckStatusFail
+	}
+}
+
+// String implements the fmt.Stringer interface
+func (r *CheckResult) String() string {
+	if r.Err != nil && !r.Fixed {
+		return r.Err.Error()
+	}
+	return r.Msg
+}
+
+// CheckHostOptions are the expectations of a host to be checked
+type CheckHostOptions struct {
+	User      string   // the deploy user
+	OS        string   // the expected kernel name
+	Arch      string   // the expected cpu arch
+	DataDirs  []string // the data dirs of the instances on the host
+	Ports     []int    // the ports used by the instances on the host
+	SkipPorts bool     // skip checking the ports, e.g. the instances are running
+}
+
+// CheckHost runs all the check items on the host, the executor of the host must be ready in the context.
+func CheckHost(ctx context.Context, host string, opt *CheckHostOptions) []*CheckResult {
+	e := ctxt.GetInner(ctx).Get(host)
+	exec := func(cmd string) (string, error) {
+		stdout, stderr, err := e.Execute(ctx, cmd, false)
+		if err != nil {
+			if msg := strings.TrimSpace(string(stderr)); msg != "" {
+				return "", errors.Errorf("failed to run `%s`: %s", cmd, msg)
+			}
+			return "", errors.WithMessagef(err, "failed to run `%s`", cmd)
+		}
+		return strings.TrimSpace(string(stdout)), nil
+	}
+
+	results := []*CheckResult{checkOSArch(exec, opt)}
+	results = append(results, checkDisk(exec, opt)...)
+	results = append(results,
+		checkMem(exec),
+		checkCPU(exec),
+		checkLimits(exec),
+		checkSysctl(exec),
+		checkSwap(exec),
+		checkTHP(exec),
+		checkTimeSync(exec),
+	)
+	if !opt.SkipPorts {
+		results = append(results, checkPorts(exec, opt))
+	}
+	results = append(results, checkGlibc(exec), checkSystemd(exec))
+	return results
+}
+
+// FixHost tries to fix the failed check items on the host with root permission, the
+// items fixed successfully are marked as fixed, the others are kept unchanged.
+func FixHost(ctx context.Context, host string, opt *CheckHostOptions, results []*CheckResult) {
+	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
+	e := ctxt.GetInner(ctx).Get(host)
+
+	for _, r := range results {
+		if r.Passed() {
+			continue
+		}
+		cmd := fixCommand(r, opt)
+		if cmd == "" {
+			continue
+		}
+		if _, stderr, err := e.Execute(ctx, cmd, true); err != nil {
+			logger.Warnf("Failed to fix %s on %s: %s", r.Name, host, strings.TrimSpace(string(stderr)))
+			continue
+		}
+		r.Fixed = true
+		r.Msg = fmt.Sprintf("%s, fixed", r.Err)
+	}
+}
+
+// fixCommand returns the command to fix the check item, empty if it can not be fixed automatically.
+// The command is executed by `sudo bash -c "<cmd>"`, so double quotes and `$` must not be used.
+func fixCommand(r *CheckResult, opt *CheckHostOptions) string {
+	switch r.Name {
+	case CheckNameTHP:
+		return fmt.Sprintf("echo never > %s && echo never > %s", thpEnabledPath, thpDefragPath)
+	case CheckNameSwap:
+		return "swapoff -a && sed -i '/ swap / s/^[^#]/#&/' /etc/fstab"
+	case CheckNameSysctl:
+		return r.fix
+	case CheckNameLimits:
+		if opt.User == "" {
+			return ""
+		}
+		return fmt.Sprintf("printf '%[1]s soft nofile %[2]d\\n%[1]s hard nofile %[2]d\\n' > /etc/security/limits.d/gemix-%[1]s.conf",
+			opt.User, checkMinOpenFiles)
+	}
+	return ""
+}
+
+type checkExec func(cmd string) (string, error)
+
+func checkOSArch(exec checkExec, opt *CheckHostOptions) *CheckResult {
+	result := &CheckResult{Name: CheckNameOSArch}
+	out, err := exec("uname -s && uname -m")
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		result.Err = errors.Errorf("unknown output of uname: %s", out)
+		return result
+	}
+
+	osName := strings.ToLower(fields[0])
+	arch := strings.ToLower(fields[1])
+	switch arch {
+	case "x86_64":
+		arch = "amd64"
+	case "aarch64":
+		arch = "arm64"
+	}
+	result.Msg = fmt.Sprintf("%s/%s", osName, arch)
+	if (opt.OS != "" && osName != opt.OS) || (opt.Arch != "" && arch != opt.Arch) {
+		result.Err = errors.Errorf("%s/%s is expected, but got %s/%s", opt.OS, opt.Arch, osName, arch)
+	}
+	return result
+}
+
+func checkDisk(exec checkExec, opt *CheckHostOptions) []*CheckResult {
+	var results []*CheckResult
+	for _, dir := range opt.DataDirs {
+		result := &CheckResult{Name: CheckNameDisk}
+		results = append(results, result)
+
+		// the data dir may not be created yet, check the nearest existing parent
+		var cmds []string
+		for d := dir; ; d = filepath.Dir(d) {
+			cmds = append(cmds, fmt.Sprintf("df -Pk %s 2>/dev/null", d))
+			if d == "/" || d == "." {
+				break
+			}
+		}
+		out, err := exec(strings.Join(cmds, " || "))
+		if err != nil {
+			result.Err = err
+			continue
+		}
+		usage, err := ParseDiskUsage(dir, out)
+		if err != nil {
+			result.Err = err
+			continue
+		}
+
+		avail := usage.Available
+		result.Msg = fmt.Sprintf("%s: %s available on %s", dir, ReadableKB(avail), usage.MountPoint)
+		switch {
+		case avail < checkFatalDiskFreeKB:
+			result.Err = errors.Errorf("%s: only %s available on %s", dir, ReadableKB(avail), usage.MountPoint)
+		case avail < checkMinDiskFreeKB:
+			result.Err = errors.Errorf("%s: only %s available on %s, %s is recommended",
+				dir, ReadableKB(avail), usage.MountPoint, ReadableKB(checkMinDiskFreeKB))
+			result.Warn = true
+		}
+	}
+	return results
+}
+
+func checkMem(exec checkExec) *CheckResult {
+	result := &CheckResult{Name: CheckNameMem, Warn: true}
+	total, err := readMemInfo(exec, "MemTotal")
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Msg = fmt.Sprintf("memory size is %s", ReadableKB(total))
+	if total < checkMinMemKB {
+		result.Err = errors.Errorf("memory size is %s, %s is recommended", ReadableKB(total), ReadableKB(checkMinMemKB))
+	}
+	return result
+}
+
+func checkCPU(exec checkExec) *CheckResult {
+	result := &CheckResult{Name: CheckNameCPU, Warn: true}
+	out, err := exec("nproc")
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	cores, err := strconv.Atoi(out)
+	if err != nil {
+		result.Err = errors.Errorf("unknown output of nproc: %s", out)
+		return result
+	}
+	result.Msg = fmt.Sprintf("number of CPU cores is %d", cores)
+	if cores < checkMinCPUCores {
+		result.Err = errors.Errorf("number of CPU cores is %d, %d is recommended", cores, checkMinCPUCores)
+	}
+	return result
+}
+
+func checkLimits(exec checkExec) *CheckResult {
+	result := &CheckResult{Name: CheckNameLimits, Warn: true}
+	out, err := exec("ulimit -n")
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Msg = fmt.Sprintf("max open files is %s", out)
+	if out == "unlimited" {
+		return result
+	}
+	n, err := strconv.Atoi(out)
+	if err != nil {
+		result.Err = errors.Errorf("unknown output of ulimit: %s", out)
+		return result
+	}
+	if n < checkMinOpenFiles {
+		result.Err = errors.Errorf("max open files is %d, %d is recommended", n, checkMinOpenFiles)
+	}
+	return result
+}
+
+func checkSysctl(exec checkExec) *CheckResult {
+	result := &CheckResult{Name: CheckNameSysctl, Warn: true}
+	var keys []string
+	for _, p := range sysctlParams {
+		keys = append(keys, p.key)
+	}
+	out, err := exec(fmt.Sprintf("sysctl -n %s", strings.Join(keys, " ")))
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	values := strings.Fields(out)
+	if len(values) != len(sysctlParams) {
+		result.Err = errors.Errorf("unknown output of sysctl: %s", out)
+		return result
+	}
+
+	var bad, fixes []string
+	for i, p := range sysctlParams {
+		v, err := strconv.Atoi(values[i])
+		if err != nil {
+			bad = append(bad, fmt.Sprintf("%s = %s", p.key, values[i]))
+			fixes = append(fixes, sysctlFixCommand(p.key, p.value))
+			continue
+		}
+		if p.key == "vm.swappiness" {
+			if v != p.value {
+				bad = append(bad, fmt.Sprintf("%s = %d, %d is expected", p.key, v, p.value))
+				fixes = append(fixes, sysctlFixCommand(p.key, p.value))
+			}
+		} else if v < p.value {
+			bad = append(bad, fmt.Sprintf("%s = %d, %d is expected", p.key, v, p.value))
+			// the minimal value never lowers the current one
+			target := p.value
+			if v > target {
+				target = v
+			}
+			fixes = append(fixes, sysctlFixCommand(p.key, target))
+		}
+	}
+	result.Msg = "kernel parameters are good"
+	if len(bad) > 0 {
+		result.Err = errors.New(strings.Join(bad, "; "))
+		result.fix = strings.Join(fixes, " && ")
+	}
+	return result
+}
+
+// sysctlFixCommand replaces the line of the key in the sysctl config of gemix and applies
+// the value, the other keys in the config are kept and not applied again
+func sysctlFixCommand(key string, value int) string {
+	return fmt.Sprintf("touch %[1]s && sed -i '/^%[2]s *=/d' %[1]s && echo '%[2]s = %[3]d' >> %[1]s && sysctl -w %[2]s=%[3]d",
+		sysctlConfPath, key, value)
+}
+
+func checkSwap(exec checkExec) *CheckResult {
+	result := &CheckResult{Name: CheckNameSwap, Warn: true}
+	total, err := readMemInfo(exec, "SwapTotal")
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Msg = "swap is disabled"
+	if total > 0 {
+		result.Err = errors.Errorf("swap is enabled with %s, it's recommended to disable it", ReadableKB(total))
+	}
+	return result
+}
+
+func checkTHP(exec checkExec) *CheckResult {
+	result := &CheckResult{Name: CheckNameTHP, Warn: true}
+	out, err := exec(fmt.Sprintf("cat %s", thpEnabledPath))
+	if err != nil {
+		// the kernel is built without THP
+		result.Msg = "transparent huge pages is not supported"
+		return result
+	}
+	result.Msg = "transparent huge pages is disabled"
+	if !strings.Contains(out, "[never]") {
+		result.Err = errors.Errorf("transparent huge pages is enabled (%s), it's recommended to disable it", out)
+	}
+	return result
+}
+
+func checkTimeSync(exec checkExec) *CheckResult {
+	result := &CheckResult{Name: CheckNameTimeSync, Warn: true}
+	services := []string{"chronyd", "chrony", "ntpd", "ntp", "systemd-timesyncd"}
+	// is-active exits with non-zero if any of the services is inactive
+	out, _ := exec(fmt.Sprintf("systemctl is-active %s || true", strings.Join(services, " ")))
+	for i, state := range strings.Split(out, "\n") {
+		if strings.TrimSpace(state) == "active" && i < len(services) {
+			result.Msg = fmt.Sprintf("time is synchronized by %s", services[i])
+			return result
+		}
+	}
+	result.Err = errors.New("none of chrony and ntpd is running, the time of hosts may be inconsistent")
+	return result
+}
+
+func checkPorts(exec checkExec, opt *CheckHostOptions) *CheckResult {
+	result := &CheckResult{Name: CheckNamePorts}
+	out, err := exec("ss -lnt 2>/dev/null || netstat -lnt")
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	listening := map[int]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		addr := fields[3]
+		port, err := strconv.Atoi(addr[strings.LastIndex(addr, ":")+1:])
+		if err != nil {
+			continue
+		}
+		listening[port] = true
+	}
+
+	var used []string
+	ports := append([]int{}, opt.Ports...)
+	sort.Ints(ports)
+	for _, port := range ports {
+		if listening[port] {
+			used = append(used, strconv.Itoa(port))
+		}
+	}
+	result.Msg = "ports are not in use"
+	if len(used) > 0 {
+		result.Err = errors.Errorf("ports %s are already in use", strings.Join(used, ","))
+	}
+	return result
+}
+
+func checkGlibc(exec checkExec) *CheckResult {
+	result := &CheckResult{Name: CheckNameGlibc}
+	out, err := exec("ldd --version 2>&1 | head -n 1")
+	fields := strings.Fields(out)
+	if err != nil || len(fields) == 0 {
+		result.Err = errors.New("failed to detect the version of glibc")
+		result.Warn = true
+		return result
+	}
+
+	ver := fields[len(fields)-1]
+	result.Msg = fmt.Sprintf("glibc version is %s", ver)
+	if !semver.IsValid("v"+ver) || semver.Compare("v"+ver, checkMinGlibc) < 0 {
+		result.Err = errors.Errorf("glibc version is %s, %s or later is required", ver, strings.TrimPrefix(checkMinGlibc, "v"))
+	}
+	return result
+}
+
+func checkSystemd(exec checkExec) *CheckResult {
+	result := &CheckResult{Name: CheckNameSystemd}
+	out, err := exec("systemctl --version | head -n 1")
+	if err != nil || !strings.HasPrefix(out, "systemd") {
+		result.Err = errors.New("systemd is not found, it's required to manage the instances")
+		return result
+	}
+	result.Msg = out
+	return result
+}
+
+// readMemInfo reads the value in KB of the key in /proc/meminfo
+func readMemInfo(exec checkExec, key string) (uint64, error) {
+	out, err := exec(fmt.Sprintf("grep '^%s:' /proc/meminfo", key))
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(out)
+	if len(fields) < 2 {
+		return 0, errors.Errorf("unknown output of /proc/meminfo: %s", out)
+	}
+	v, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("unknown output of /proc/meminfo: %s", out)
+	}
+	return v, nil
+}
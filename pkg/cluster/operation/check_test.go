@@ -0,0 +1,104 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	logprinter "github.com/openGemini/gemix/pkg/logger/printer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeExecutor returns the output of the first command prefix matched
+type fakeExecutor map[string]string
+
+func (f fakeExecutor) Execute(ctx context.Context, cmd string, sudo bool, timeout ...time.Duration) ([]byte, []byte, error) {
+	for prefix, out := range f {
+		if strings.HasPrefix(cmd, prefix) {
+			return []byte(out), nil, nil
+		}
+	}
+	return nil, []byte("command not found"), errors.New("exit status 127")
+}
+
+func (f fakeExecutor) Transfer(ctx context.Context, src, dst string, download bool, limit int, compress bool) error {
+	return nil
+}
+
+func TestCheckHost(t *testing.T) {
+	ctx := ctxt.New(context.Background(), 1, logprinter.NewLogger(""))
+	ctxt.GetInner(ctx).SetExecutor("h1", fakeExecutor{
+		"uname":           "Linux\nx86_64\n",
+		"df -Pk /data/ts": "Filesystem 1024-blocks Used Available Capacity Mounted on\n/dev/vda1 103081248 6254160 524288 93% /\n",
+		"grep '^MemTotal": "MemTotal:       16303360 kB\n",
+		"grep '^SwapTota": "SwapTotal:       2097148 kB\n",
+		"nproc":           "8\n",
+		"ulimit -n":       "1024\n",
+		"sysctl -n":       "60\n1000000\n4096\n",
+		"cat /sys/kernel": "[always] madvise never\n",
+		"systemctl is-":   "inactive\ninactive\ninactive\nactive\ninactive\n",
+		"ss -lnt":         "State Recv-Q Send-Q Local Address:Port Peer Address:Port\nLISTEN 0 128 0.0.0.0:22 0.0.0.0:*\nLISTEN 0 128 [::]:8091 [::]:*\n",
+		"ldd --version":   "ldd (GNU libc) 2.17\n",
+		"systemctl --ver": "systemd 219\n",
+	})
+
+	results := CheckHost(ctx, "h1", &CheckHostOptions{
+		User:     "gemini",
+		OS:       "linux",
+		Arch:     "amd64",
+		DataDirs: []string{"/data/ts"},
+		Ports:    []int{8091, 8092},
+	})
+
+	status := map[string]string{}
+	for _, r := range results {
+		status[r.Name] = r.Status()
+	}
+	assert.Equal(t, map[string]string{
+		CheckNameOSArch:   CheckStatusPass,
+		CheckNameDisk:     CheckStatusFail,
+		CheckNameMem:      CheckStatusPass,
+		CheckNameCPU:      CheckStatusPass,
+		CheckNameLimits:   CheckStatusWarn,
+		CheckNameSysctl:   CheckStatusWarn,
+		CheckNameSwap:     CheckStatusWarn,
+		CheckNameTHP:      CheckStatusWarn,
+		CheckNameTimeSync: CheckStatusPass,
+		CheckNamePorts:    CheckStatusFail,
+		CheckNameGlibc:    CheckStatusPass,
+		CheckNameSystemd:  CheckStatusPass,
+	}, status)
+
+	for _, r := range results {
+		switch r.Name {
+		case CheckNamePorts:
+			assert.Equal(t, "ports 8091 are already in use", r.String())
+		case CheckNameTimeSync:
+			assert.Equal(t, "time is synchronized by ntp", r.String())
+		case CheckNameSysctl:
+			// only the failed keys are fixed, fs.file-max is kept
+			assert.Equal(t, "touch /etc/sysctl.d/99-gemix.conf && sed -i '/^vm.swappiness *=/d' /etc/sysctl.d/99-gemix.conf && "+
+				"echo 'vm.swappiness = 0' >> /etc/sysctl.d/99-gemix.conf && sysctl -w vm.swappiness=0 && "+
+				"touch /etc/sysctl.d/99-gemix.conf && sed -i '/^net.core.somaxconn *=/d' /etc/sysctl.d/99-gemix.conf && "+
+				"echo 'net.core.somaxconn = 32768' >> /etc/sysctl.d/99-gemix.conf && sysctl -w net.core.somaxconn=32768",
+				fixCommand(r, &CheckHostOptions{}))
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DiskUsage is the usage of the filesystem where a path is, the sizes are in KiB
+type DiskUsage struct {
+	Path       string `json:"path"`
+	Filesystem string `json:"filesystem"`
+	MountPoint string `json:"mount_point"`
+	Total      uint64 `json:"total"`
+	Used       uint64 `json:"used"`
+	Available  uint64 `json:"available"`
+}
+
+// ParseDiskUsage parses the output of `df -Pk` for the path, the filesystem is
+// in the last line, e.g.
+// Filesystem     1024-blocks     Used Available Capacity Mounted on
+// /dev/vda1        103079844 20150384  78520116      21% /
+func ParseDiskUsage(path, output string) (*DiskUsage, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 6 {
+		return nil, errors.Errorf("unexpected output of df for %s: %s", path, output)
+	}
+
+	usage := &DiskUsage{
+		Path:       path,
+		Filesystem: fields[0],
+		MountPoint: strings.Join(fields[5:], " "),
+	}
+	var err error
+	for i, v := range []*uint64{&usage.Total, &usage.Used, &usage.Available} {
+		if *v, err = strconv.ParseUint(fields[i+1], 10, 64); err != nil {
+			return nil, errors.Errorf("unexpected output of df for %s: %s", path, output)
+		}
+	}
+	return usage, nil
+}
+
+// ReadableKB formats the size in KiB
+func ReadableKB(kb uint64) string {
+	if kb < 1024 {
+		return fmt.Sprintf("%dKiB", kb)
+	}
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB"}
+	size := float64(kb)
+	i := 0
+	for size >= 1024 && i < len(units)-1 {
+		size /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f%s", size, units[i])
+}
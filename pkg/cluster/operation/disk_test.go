@@ -0,0 +1,80 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDiskUsage(t *testing.T) {
+	cases := []struct {
+		output   string
+		expected *DiskUsage
+	}{
+		{
+			output: "Filesystem     1024-blocks     Used Available Capacity Mounted on\n" +
+				"/dev/vda1        103079844 20150384  78520116      21% /\n",
+			expected: &DiskUsage{Path: "/data", Filesystem: "/dev/vda1", MountPoint: "/",
+				Total: 103079844, Used: 20150384, Available: 78520116},
+		},
+		{
+			// the long filesystem name is not wrapped with -P, and the mount point may have spaces
+			output: "Filesystem 1024-blocks Used Available Capacity Mounted on\n" +
+				"/dev/mapper/vg_data-lv_gemini 524288 1024 523264 1% /mnt/data disk\n",
+			expected: &DiskUsage{Path: "/data", Filesystem: "/dev/mapper/vg_data-lv_gemini", MountPoint: "/mnt/data disk",
+				Total: 524288, Used: 1024, Available: 523264},
+		},
+		{
+			// the header is missing
+			output: "/dev/vda1 1024 0 1024 0% /data\n",
+			expected: &DiskUsage{Path: "/data", Filesystem: "/dev/vda1", MountPoint: "/data",
+				Total: 1024, Used: 0, Available: 1024},
+		},
+		{output: ""},
+		{output: "Filesystem     1024-blocks     Used Available Capacity Mounted on\n"},
+		{output: "/dev/vda1 1024 0 1024 0%\n"},
+		{output: "/dev/vda1 1024 -1 1024 0% /\n"},
+		{output: "df: /data: No such file or directory\n"},
+	}
+	for _, c := range cases {
+		usage, err := ParseDiskUsage("/data", c.output)
+		if c.expected == nil {
+			require.Error(t, err, c.output)
+			assert.Contains(t, err.Error(), "unexpected output of df for /data")
+			continue
+		}
+		require.NoError(t, err, c.output)
+		assert.Equal(t, c.expected, usage)
+	}
+}
+
+func TestReadableKB(t *testing.T) {
+	cases := map[uint64]string{
+		0:                         "0KiB",
+		1023:                      "1023KiB",
+		1024:                      "1.0MiB",
+		1536:                      "1.5MiB",
+		10 * 1024 * 1024:          "10.0GiB",
+		3 * 1024 * 1024 * 1024:    "3.0TiB",
+		2048 * 1024 * 1024 * 1024: "2.0PiB",
+		1 << 52:                   "4096.0PiB",
+	}
+	for kb, expected := range cases {
+		assert.Equal(t, expected, ReadableKB(kb), kb)
+	}
+}
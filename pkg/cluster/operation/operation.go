@@ -1,6 +1,7 @@
 package operation
 
 import (
+	"github.com/openGemini/gemix/pkg/cluster/executor"
 	"github.com/openGemini/gemix/pkg/cluster/spec"
 	"github.com/openGemini/gemix/pkg/set"
 )
@@ -9,18 +10,19 @@ import (
 type Options struct {
 	Roles               []string
 	Nodes               []string
-	Force               bool   // Option for upgrade/tls subcommand
-	SSHTimeout          uint64 // timeout in seconds when connecting an SSH server
-	OptTimeout          uint64 // timeout in seconds for operations that support it, not to confuse with SSH timeout
-	APITimeout          uint64 // timeout in seconds for API operations that support it, like transferring store leader
-	IgnoreConfigCheck   bool   // should we ignore the config check result after init config
-	Concurrency         int    // max number of parallel tasks to run
-	SSHProxyHost        string // the ssh proxy host
-	SSHProxyPort        int    // the ssh proxy port
-	SSHProxyUser        string // the ssh proxy user
-	SSHProxyIdentity    string // the ssh proxy identity file
-	SSHProxyUsePassword bool   // use password instead of identity file for ssh proxy connection
-	SSHProxyTimeout     uint64 // timeout in seconds when connecting the proxy host
+	Force               bool             // Option for upgrade/tls subcommand
+	SSHTimeout          uint64           // timeout in seconds when connecting an SSH server
+	OptTimeout          uint64           // timeout in seconds for operations that support it, not to confuse with SSH timeout
+	APITimeout          uint64           // timeout in seconds for API operations that support it, like transferring store leader
+	IgnoreConfigCheck   bool             // should we ignore the config check result after init config
+	Concurrency         int              // max number of parallel tasks to run
+	SSHProxyHost        string           // the ssh proxy host
+	SSHProxyPort        int              // the ssh proxy port
+	SSHProxyUser        string           // the ssh proxy user
+	SSHProxyIdentity    string           // the ssh proxy identity file
+	SSHProxyUsePassword bool             // use password instead of identity file for ssh proxy connection
+	SSHProxyTimeout     uint64           // timeout in seconds when connecting the proxy host
+	SSHType             executor.SSHType // the ssh type: 'builtin', 'none', or decided by the host if empty
 	// SSHCustomScripts    SSHCustomScripts // custom scripts to be executed during the operation
 
 	// What type of things should we cleanup in clean command
@@ -33,6 +35,8 @@ type Options struct {
 	RetainDataNodes []string
 
 	DisplayMode string // the output format
+	DryRun      bool   // only record the operations on the hosts instead of performing them
+	NoRollback  bool   // keep the changes on the hosts if the operation fails
 	// Operation   Operation
 }
 
@@ -0,0 +1,114 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operation
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"github.com/openGemini/gemix/pkg/cluster/api"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	logprinter "github.com/openGemini/gemix/pkg/logger/printer"
+	"github.com/openGemini/gemix/pkg/set"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// ScaleIn removes the instances specified by options.Nodes from the cluster. The PTs of
+// ts-store are migrated to the other nodes via ts-meta before the instances are stopped.
+func ScaleIn(
+	ctx context.Context,
+	cluster spec.Topology,
+	options Options,
+	tlsCfg *tls.Config,
+) error {
+	if len(options.Nodes) == 0 {
+		return errors.New("no instance is specified to scale in")
+	}
+
+	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
+	nodes := set.NewStringSet(options.Nodes...)
+
+	// the surviving ts-meta instances are used to offload ts-store
+	var metaAddrs []string
+	var stores []spec.Instance
+	instCount := map[string]int{}
+	cluster.IterInstance(func(inst spec.Instance) {
+		if nodes.Exist(inst.ID()) {
+			if inst.ComponentName() == spec.ComponentTSStore {
+				stores = append(stores, inst)
+			}
+			return
+		}
+		if inst.ComponentName() == spec.ComponentTSMeta {
+			metaAddrs = append(metaAddrs, utils.JoinHostPort(inst.GetManageHost(), inst.GetPort()))
+		}
+		instCount[inst.GetManageHost()]++
+	})
+
+	if len(stores) > 0 {
+		client := api.NewTSMetaClient(ctx, metaAddrs, time.Second*time.Duration(options.APITimeout), tlsCfg)
+		for _, ins := range stores {
+			addr := utils.JoinHostPort(ins.GetHost(), ins.GetPort())
+			logger.Infof("Offloading the PTs of ts-store %s", ins.ID())
+			err := client.OffloadStore(addr)
+			if err == nil {
+				err = client.WaitStoreOffloaded(ins.(*spec.TSStoreInstance).IsAddr, nil)
+			}
+			if err != nil {
+				if !options.Force {
+					return errors.WithMessagef(err, "failed to offload ts-store %s", ins.ID())
+				}
+				logger.Warnf("Failed to offload ts-store %s, ignored because of the force option: %s", ins.ID(), err)
+			}
+		}
+	}
+
+	for _, comp := range cluster.ComponentsByStopOrder() {
+		insts := FilterInstance(comp.Instances(), nodes)
+		if len(insts) == 0 {
+			continue
+		}
+		if err := StopComponent(ctx, insts, options, options.Force); err != nil && !options.Force {
+			return errors.WithMessagef(err, "failed to stop %s", comp.Name())
+		}
+		if err := DestroyComponent(ctx, insts, cluster, options); err != nil && !options.Force {
+			return errors.WithMessagef(err, "failed to destroy %s", comp.Name())
+		}
+	}
+
+	// destroy ts-monitor on the hosts without any instance left
+	monitoredOptions := cluster.GetMonitoredOptions()
+	if monitoredOptions == nil || !monitoredOptions.TSMonitorEnabled {
+		return nil
+	}
+	destroyed := set.NewStringSet()
+	cluster.IterInstance(func(inst spec.Instance) {
+		host := inst.GetManageHost()
+		if !nodes.Exist(inst.ID()) || instCount[host] > 0 || destroyed.Exist(host) {
+			return
+		}
+		destroyed.Insert(host)
+		if err := StopMonitored(ctx, []string{host}, set.NewStringSet(), options.OptTimeout); err != nil {
+			logger.Warnf("Failed to stop ts-monitor on %s: %s", host, err)
+		}
+		if err := DestroyMonitored(ctx, inst, monitoredOptions, options.OptTimeout); err != nil {
+			logger.Warnf("Failed to destroy ts-monitor on %s: %s", host, err)
+		}
+	})
+
+	return nil
+}
@@ -203,15 +203,32 @@ func DestroyMonitored(ctx context.Context, inst spec.Instance, options *spec.TSM
 	return nil
 }
 
-// CleanupComponent cleanup the instances
-func CleanupComponent(ctx context.Context, delFileMaps map[string]set.StringSet) error {
+// CleanupComponent cleanup the instances, the files of delFileMaps are deleted and the
+// dirs of delDirMaps are emptied, including the hidden files in them
+func CleanupComponent(ctx context.Context, delFileMaps, delDirMaps map[string]set.StringSet) error {
 	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
-	for host, delFiles := range delFileMaps {
+	hosts := set.NewStringSet()
+	for host := range delFileMaps {
+		hosts.Insert(host)
+	}
+	for host := range delDirMaps {
+		hosts.Insert(host)
+	}
+	for host := range hosts {
 		e := ctxt.GetInner(ctx).Get(host)
 		logger.Infof("Cleanup instance %s", host)
-		logger.Debugf("Deleting paths on %s: %s", host, strings.Join(delFiles.Slice(), " "))
+
+		var cmds []string
+		if delFiles := delFileMaps[host].Slice(); len(delFiles) > 0 {
+			logger.Debugf("Deleting paths on %s: %s", host, strings.Join(delFiles, " "))
+			cmds = append(cmds, fmt.Sprintf("rm -rf %s", strings.Join(delFiles, " ")))
+		}
+		for _, dir := range delDirMaps[host].Slice() {
+			logger.Debugf("Deleting the contents of %s on %s", dir, host)
+			cmds = append(cmds, fmt.Sprintf("(test ! -d %[1]s || find %[1]s -mindepth 1 -delete)", dir))
+		}
 		c := module.ShellModuleConfig{
-			Command:  fmt.Sprintf("rm -rf %s;", strings.Join(delFiles.Slice(), " ")),
+			Command:  strings.Join(cmds, " && "),
 			Sudo:     true, // the .service files are in a directory owned by root
 			Chdir:    "",
 			UseShell: true,
@@ -284,9 +301,9 @@ func DestroyComponent(ctx context.Context, instances []spec.Instance, cls spec.T
 			delPaths.Insert(filepath.Join(deployDir, "conf"))
 			delPaths.Insert(filepath.Join(deployDir, "bin"))
 			delPaths.Insert(filepath.Join(deployDir, "scripts"))
-			//if cls.BaseTopo().GlobalOptions.TLSEnabled {
-			//	delPaths.Insert(filepath.Join(deployDir, spec.TLSCertKeyDir))
-			//}
+			if cls.BaseTopo().GlobalOptions.TLSEnabled {
+				delPaths.Insert(filepath.Join(deployDir, spec.TLSCertKeyDir))
+			}
 			// only delete path if it is not used by any other instance in the cluster
 			if strings.HasPrefix(logDir, deployDir) && cls.CountDir(ins.GetManageHost(), logDir) == 1 {
 				delPaths.Insert(logDir)
@@ -0,0 +1,83 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operation
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	logprinter "github.com/openGemini/gemix/pkg/logger/printer"
+	"github.com/openGemini/gemix/pkg/set"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shellExecutor runs the commands with the local shell, sudo is ignored
+type shellExecutor struct{}
+
+func (shellExecutor) Execute(ctx context.Context, cmd string, sudo bool, timeout ...time.Duration) ([]byte, []byte, error) {
+	out, err := exec.CommandContext(ctx, "sh", "-c", cmd).CombinedOutput()
+	return out, nil, err
+}
+
+func (shellExecutor) Transfer(ctx context.Context, src, dst string, download bool, limit int, compress bool) error {
+	return nil
+}
+
+func TestCleanupComponent(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		"data/meta/1.raft",
+		"data/.hidden/wal",
+		"data/.lock",
+		"logs/ts-sql.log",
+		"logs/ts-sql.log.1.gz",
+		"logs/audit/.audit.log",
+		"logs/ts-sql.out",
+	}
+	for _, f := range files {
+		p := filepath.Join(dir, f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, nil, 0644))
+	}
+
+	ctx := ctxt.New(context.Background(), 1, logprinter.NewLogger(""))
+	ctxt.GetInner(ctx).SetExecutor("h1", shellExecutor{})
+	ctxt.GetInner(ctx).SetExecutor("h2", shellExecutor{})
+
+	err := CleanupComponent(ctx,
+		map[string]set.StringSet{"h1": set.NewStringSet(filepath.Join(dir, "logs", "*.log*"))},
+		map[string]set.StringSet{
+			"h1": set.NewStringSet(filepath.Join(dir, "data")),
+			"h2": set.NewStringSet(filepath.Join(dir, "logs", "audit"), filepath.Join(dir, "missing")),
+		})
+	require.NoError(t, err)
+
+	// the dirs are kept and emptied, including the hidden files
+	for _, d := range []string{"data", "logs/audit"} {
+		entries, err := os.ReadDir(filepath.Join(dir, d))
+		require.NoError(t, err)
+		assert.Empty(t, entries, d)
+	}
+	assert.NoFileExists(t, filepath.Join(dir, "logs", "ts-sql.log"))
+	assert.NoFileExists(t, filepath.Join(dir, "logs", "ts-sql.log.1.gz"))
+	assert.FileExists(t, filepath.Join(dir, "logs", "ts-sql.out"))
+	assert.NoDirExists(t, filepath.Join(dir, "missing"))
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operation
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/openGemini/gemix/pkg/cluster/api"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	logprinter "github.com/openGemini/gemix/pkg/logger/printer"
+	"github.com/openGemini/gemix/pkg/set"
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// Upgrade the cluster by restarting the instances one by one, the order is:
+// ts-meta followers, ts-meta leader, ts-store, ts-sql and then the others.
+// The prepare func is called right before each instance is restarted to replace its
+// binaries and configs, so the instances not restarted yet are kept intact and the
+// restarted ones keep running if any of the others fails.
+func Upgrade(
+	ctx context.Context,
+	topo spec.Topology,
+	options Options,
+	tlsCfg *tls.Config,
+	prepare func(ctx context.Context, ins spec.Instance) error,
+) error {
+	// only the components from openGemini package are upgraded
+	return rollingRestart(ctx, topo, options, tlsCfg, "Upgrading", func(ins spec.Instance) bool {
+		return ins.ComponentSource() == spec.ComponentOpenGemini
+	}, prepare)
+}
+
+// RollingRestart restarts the instances one by one in the same order as Upgrade.
+func RollingRestart(
+	ctx context.Context,
+	topo spec.Topology,
+	options Options,
+	tlsCfg *tls.Config,
+) error {
+	return rollingRestart(ctx, topo, options, tlsCfg, "Restarting", nil, nil)
+}
+
+// rollingRestart restarts the filtered instances one by one, the instances are skipped
+// if the filter func returns false, and the prepare func is called before each restart.
+func rollingRestart(
+	ctx context.Context,
+	topo spec.Topology,
+	options Options,
+	tlsCfg *tls.Config,
+	action string,
+	filter func(ins spec.Instance) bool,
+	prepare func(ctx context.Context, ins spec.Instance) error,
+) error {
+	roleFilter := set.NewStringSet(options.Roles...)
+	nodeFilter := set.NewStringSet(options.Nodes...)
+	components := topo.ComponentsByStartOrder()
+	components = FilterComponent(components, roleFilter)
+	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
+
+	for _, component := range components {
+		instances := FilterInstance(component.Instances(), nodeFilter)
+		if len(instances) == 0 {
+			continue
+		}
+		if component.Name() == spec.ComponentTSMeta {
+			instances = sortTSMetaByLeader(ctx, instances, options, tlsCfg)
+		}
+
+		logger.Infof("%s component %s", action, component.Name())
+		for _, ins := range instances {
+			if filter != nil && !filter(ins) {
+				continue
+			}
+			if prepare != nil {
+				if err := prepare(ctx, ins); err != nil {
+					return errors.WithMessagef(err, "failed to prepare %s", ins.ID())
+				}
+			}
+			if err := restartInstance(ctx, ins, options.OptTimeout, tlsCfg); err != nil {
+				return errors.WithMessagef(err, "failed to restart %s", ins.ID())
+			}
+		}
+	}
+	return nil
+}
+
+// sortTSMetaByLeader moves the ts-meta leader to the end of the instances, so that the
+// leader is restarted last and the raft leadership changes only once.
+func sortTSMetaByLeader(ctx context.Context, instances []spec.Instance, options Options, tlsCfg *tls.Config) []spec.Instance {
+	logger := ctx.Value(logprinter.ContextKeyLogger).(*logprinter.Logger)
+
+	var addrs []string
+	for _, ins := range instances {
+		addrs = append(addrs, utils.JoinHostPort(ins.GetManageHost(), ins.GetPort()))
+	}
+	leader, err := api.NewTSMetaClient(ctx, addrs, time.Second*time.Duration(options.APITimeout), tlsCfg).GetLeader()
+	if err != nil {
+		logger.Warnf("Failed to get the leader of ts-meta, restart ts-meta in the default order: %s", err)
+		return instances
+	}
+
+	leaderHost, port, err := net.SplitHostPort(leader)
+	if err != nil {
+		logger.Warnf("Unrecognized ts-meta leader address %s, restart ts-meta in the default order", leader)
+		return instances
+	}
+	leaderPort, _ := strconv.Atoi(port)
+
+	var sorted []spec.Instance
+	var leaderIns spec.Instance
+	for _, ins := range instances {
+		if leaderIns == nil && isInstanceAddr(ins, leaderHost, leaderPort) {
+			leaderIns = ins
+			continue
+		}
+		sorted = append(sorted, ins)
+	}
+	if leaderIns != nil {
+		sorted = append(sorted, leaderIns)
+	}
+	return sorted
+}
+
+// isInstanceAddr checks whether the address is served by the instance
+func isInstanceAddr(ins spec.Instance, host string, port int) bool {
+	if host != ins.GetHost() && host != ins.GetManageHost() {
+		return false
+	}
+	for _, p := range ins.UsedPorts() {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
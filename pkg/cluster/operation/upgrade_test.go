@@ -0,0 +1,121 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	logprinter "github.com/openGemini/gemix/pkg/logger/printer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+// actionLog records the operations of the upgrade in order
+type actionLog struct {
+	mu      sync.Mutex
+	actions []string
+}
+
+func (l *actionLog) add(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.actions = append(l.actions, fmt.Sprintf(format, args...))
+}
+
+// restartExecutor records the restarted services of a host, all the commands succeed
+type restartExecutor struct {
+	host string
+	log  *actionLog
+}
+
+func (e *restartExecutor) Execute(ctx context.Context, cmd string, sudo bool, timeout ...time.Duration) ([]byte, []byte, error) {
+	if i := strings.Index(cmd, "systemctl restart "); i >= 0 {
+		e.log.add("restart %s %s", e.host, strings.Fields(cmd[i:])[2])
+	}
+	return nil, nil, nil
+}
+
+func (e *restartExecutor) Transfer(ctx context.Context, src, dst string, download bool, limit int, compress bool) error {
+	return nil
+}
+
+func newUpgradeContext(t *testing.T, log *actionLog) (context.Context, *spec.Specification) {
+	topo := &spec.Specification{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+ts_store_servers:
+  - host: h1
+  - host: h2
+ts_sql_servers:
+  - host: h1
+grafana_servers:
+  - host: h2
+`), topo))
+
+	ctx := ctxt.New(context.Background(), 1, logprinter.NewLogger(""))
+	for _, host := range []string{"h1", "h2"} {
+		ctxt.GetInner(ctx).SetExecutor(host, &restartExecutor{host: host, log: log})
+	}
+	return ctx, topo
+}
+
+func TestUpgradePrepareBeforeRestart(t *testing.T) {
+	log := &actionLog{}
+	ctx, topo := newUpgradeContext(t, log)
+
+	err := Upgrade(ctx, topo, Options{OptTimeout: 1}, nil, func(ctx context.Context, ins spec.Instance) error {
+		log.add("prepare %s", ins.ID())
+		return nil
+	})
+	require.NoError(t, err)
+	// grafana is not from the openGemini package, so it's neither replaced nor restarted
+	assert.Equal(t, []string{
+		"prepare h1:8401",
+		"restart h1 ts-store-8401.service",
+		"prepare h2:8401",
+		"restart h2 ts-store-8401.service",
+		"prepare h1:8086",
+		"restart h1 ts-sql-8086.service",
+	}, log.actions)
+}
+
+func TestUpgradePrepareFailed(t *testing.T) {
+	log := &actionLog{}
+	ctx, topo := newUpgradeContext(t, log)
+
+	err := Upgrade(ctx, topo, Options{OptTimeout: 1}, nil, func(ctx context.Context, ins spec.Instance) error {
+		log.add("prepare %s", ins.ID())
+		if ins.GetManageHost() == "h2" {
+			return errors.New("no space left on device")
+		}
+		return nil
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to prepare h2:8401: no space left on device")
+	// the instances after the failed one are kept intact
+	assert.Equal(t, []string{
+		"prepare h1:8401",
+		"restart h1 ts-store-8401.service",
+		"prepare h2:8401",
+	}, log.actions)
+}
@@ -121,8 +121,8 @@ func (c *GrafanaComponent) Instances() []Instance {
 				StatusFn: func(_ context.Context, timeout time.Duration, _ *tls.Config, _ ...string) string {
 					return statusByHost(s.GetManageHost(), s.Port, "/login", timeout, nil)
 				},
-				UptimeFn: func(_ context.Context, timeout time.Duration, tlsCfg *tls.Config) time.Duration {
-					return UptimeByHost(s.GetManageHost(), s.Port, timeout, tlsCfg)
+				UptimeFn: func(ctx context.Context, timeout time.Duration, _ *tls.Config) time.Duration {
+					return UptimeByService(ctx, s.GetManageHost(), serviceName(ComponentGrafana, s.Port), timeout)
 				},
 			},
 			topo: c.Topology,
@@ -321,7 +321,7 @@ func (i *GrafanaInstance) ScaleConfig(
 ) error {
 	s := i.topo
 	defer func() { i.topo = s }()
-	//i.topo = topo.Merge(i.topo)
+	i.topo = topo.Merge(i.topo).(*Specification)
 	return i.InitConfig(ctx, e, clusterName, clusterVersion, deployUser, paths)
 }
 
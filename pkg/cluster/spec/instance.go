@@ -80,6 +80,8 @@ type Instance interface {
 	LogDir() string
 	OS() string // only linux supported now
 	Arch() string
+	IsPatched() bool
+	SetPatched(bool)
 }
 
 // PortStarted wait until a port is being listened
@@ -161,12 +163,60 @@ func (i *BaseInstance) InitConfig(ctx context.Context, e ctxt.Executor, opt Glob
 	return nil
 }
 
-// setTLSConfig set TLS Config to support enable/disable TLS
-// baseInstance no need to configure TLS
-//
-//lint:ignore U1000 keep this
+// setTLSConfig set TLS Config to support enable/disable TLS, the RPC between the
+// components is mutual TLS, and the http api of ts-meta and ts-sql is served by https.
 func (i *BaseInstance) setTLSConfig(ctx context.Context, enableTLS bool, configs map[string]any, paths meta.DirPaths) (map[string]any, error) {
-	return nil, nil
+	if configs == nil {
+		configs = make(map[string]any)
+	}
+
+	comp := i.ComponentName()
+	var httpSection string
+	switch comp {
+	case ComponentTSMeta:
+		httpSection = "meta"
+	case ComponentTSSql:
+		httpSection = "http"
+	}
+
+	if enableTLS {
+		cert := TLSCertPath(paths.Deploy, comp)
+		key := TLSKeyPath(paths.Deploy, comp)
+		configs["spdy.tls-enable"] = true
+		configs["spdy.tls-client-auth"] = true
+		configs["spdy.tls-insecure-skip-verify"] = false
+		configs["spdy.tls-certificate"] = cert
+		configs["spdy.tls-private-key"] = key
+		configs["spdy.tls-client-certificate"] = cert
+		configs["spdy.tls-client-private-key"] = key
+		configs["spdy.tls-ca-root"] = TLSCAPath(paths.Deploy)
+		if httpSection != "" {
+			configs[httpSection+".https-enabled"] = true
+			configs[httpSection+".https-certificate"] = cert
+			configs[httpSection+".https-private-key"] = key
+		}
+		return configs, nil
+	}
+
+	// the keys are removed as TLS may be disabled after it's enabled
+	for _, key := range []string{
+		"spdy.tls-enable",
+		"spdy.tls-client-auth",
+		"spdy.tls-insecure-skip-verify",
+		"spdy.tls-certificate",
+		"spdy.tls-private-key",
+		"spdy.tls-client-certificate",
+		"spdy.tls-client-private-key",
+		"spdy.tls-ca-root",
+	} {
+		delete(configs, key)
+	}
+	if httpSection != "" {
+		for _, key := range []string{"https-enabled", "https-certificate", "https-private-key"} {
+			delete(configs, httpSection+"."+key)
+		}
+	}
+	return configs, nil
 }
 
 // TransferLocalConfigFile scp local config file to remote
@@ -220,7 +270,7 @@ func (i *BaseInstance) IteratorLocalConfigDir(ctx context.Context, local string,
 
 // MergeServerConfig merges the server configuration and overwrite the global configuration
 func (i *BaseInstance) MergeServerConfig(ctx context.Context, e ctxt.Executor, globalConf, instanceConf map[string]any, paths meta.DirPaths) error {
-	fp := filepath.Join(paths.Cache, fmt.Sprintf("%s-%s-%d.toml", i.ComponentName(), i.GetHost(), i.GetPort()))
+	fp := ServerConfigCachePath(i.ComponentName(), i.GetHost(), i.GetPort(), paths.Cache)
 	conf, err := Merge2Toml(i.ComponentName(), globalConf, instanceConf)
 	if err != nil {
 		return err
@@ -229,11 +279,21 @@ func (i *BaseInstance) MergeServerConfig(ctx context.Context, e ctxt.Executor, g
 	if err != nil {
 		return err
 	}
-	dst := filepath.Join(paths.Deploy, "conf", fmt.Sprintf("%s.toml", i.ComponentName()))
+	dst := ServerConfigPath(i.ComponentName(), paths.Deploy)
 	// transfer config
 	return e.Transfer(ctx, fp, dst, false, 0, false)
 }
 
+// ServerConfigCachePath returns the path of the rendered server config of the instance in the cache directory
+func ServerConfigCachePath(comp, host string, port int, cacheDir string) string {
+	return filepath.Join(cacheDir, fmt.Sprintf("%s-%s-%d.toml", comp, host, port))
+}
+
+// ServerConfigPath returns the path of the server config of the instance on the host
+func ServerConfigPath(comp, deployDir string) string {
+	return filepath.Join(deployDir, "conf", fmt.Sprintf("%s.toml", comp))
+}
+
 // ID returns the identifier of this instance, the ID is constructed by host:port
 func (i *BaseInstance) ID() string {
 	return fmt.Sprintf("%s:%d", i.Host, i.Port)
@@ -262,11 +322,7 @@ func (i *BaseInstance) InstanceName() string {
 
 // ServiceName implements Instance interface
 func (i *BaseInstance) ServiceName() string {
-	name := i.Name
-	if i.Port > 0 {
-		return fmt.Sprintf("%s-%d.service", name, i.Port)
-	}
-	return fmt.Sprintf("%s.service", name)
+	return serviceName(i.Name, i.Port)
 }
 
 // GetHost implements Instance interface
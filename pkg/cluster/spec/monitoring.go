@@ -134,8 +134,8 @@ func (c *TSServerComponent) Instances() []Instance {
 				StatusFn: func(_ context.Context, timeout time.Duration, _ *tls.Config, _ ...string) string {
 					return statusByHost(s.GetManageHost(), s.Port, "/status", timeout, nil)
 				},
-				UptimeFn: func(_ context.Context, timeout time.Duration, tlsCfg *tls.Config) time.Duration {
-					return UptimeByHost(s.GetManageHost(), s.Port, timeout, tlsCfg)
+				UptimeFn: func(ctx context.Context, timeout time.Duration, _ *tls.Config) time.Duration {
+					return UptimeByService(ctx, s.GetManageHost(), serviceName(ComponentTSServer, s.Port), timeout)
 				},
 			},
 			topo: c.Topology,
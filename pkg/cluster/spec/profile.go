@@ -28,6 +28,7 @@ import (
 const (
 	OpenGeminiPackageCacheDir = "packages"
 	OpenGeminiClusterDir      = "clusters"
+	OpenGeminiAuditDir        = "audit"
 )
 
 var profileDir string
@@ -85,6 +86,11 @@ func ProfilePath(subpath ...string) string {
 	return path.Join(append([]string{profileDir}, subpath...)...)
 }
 
+// AuditDir returns the directory for saving audit logs.
+func AuditDir() string {
+	return filepath.Join(profileDir, OpenGeminiAuditDir)
+}
+
 // ClusterPath returns the full path to a subpath (file or directory) of a
 // cluster, it is a subdir in the profile dir of the user, with the cluster name
 // as its name.
@@ -15,6 +15,7 @@
 package spec
 
 import (
+	"crypto/tls"
 	"fmt"
 	"path/filepath"
 	"reflect"
@@ -23,6 +24,7 @@ import (
 
 	"github.com/creasty/defaults"
 	"github.com/openGemini/gemix/pkg/meta"
+	"github.com/openGemini/gemix/pkg/set"
 	"github.com/openGemini/gemix/pkg/utils"
 	"github.com/pkg/errors"
 )
@@ -109,8 +111,9 @@ type Topology interface {
 	IterInstance(fn func(instance Instance), concurrency ...int)
 	GetMonitoredOptions() *TSMonitoredOptions
 	CountDir(host string, dir string) int // count how many time a path is used by instances in cluster
-	//TLSConfig(dir string) (*tls.Config, error)
-	//Merge(that Topology) Topology // TODO: for update
+	TLSConfig(dir string) (*tls.Config, error)
+	Merge(that Topology) Topology
+	NewPart() Topology
 	FillHostArchOrOS(hostArchmap map[string]string, fullType FullHostType) error
 	GetGrafanaConfig() map[string]string
 }
@@ -417,6 +420,65 @@ func (s *Specification) BaseTopo() *BaseTopo {
 	}
 }
 
+// NewPart implements Topology interface, it returns an empty topology with the same
+// global options, monitored options and server configs, used to parse the scale-out topology.
+func (s *Specification) NewPart() Topology {
+	return &Specification{
+		GlobalOptions:    s.GlobalOptions,
+		MonitoredOptions: s.MonitoredOptions,
+		ServerConfigs:    s.ServerConfigs,
+	}
+}
+
+// Merge returns a new Specification which sum old ones
+func (s *Specification) Merge(that Topology) Topology {
+	spec := that.(*Specification)
+	return &Specification{
+		GlobalOptions:    s.GlobalOptions,
+		MonitoredOptions: s.MonitoredOptions,
+		ServerConfigs:    s.ServerConfigs,
+		TSMetaServers:    append(append([]*TSMetaSpec{}, s.TSMetaServers...), spec.TSMetaServers...),
+		TSSqlServers:     append(append([]*TSSqlSpec{}, s.TSSqlServers...), spec.TSSqlServers...),
+		TSStoreServers:   append(append([]*TSStoreSpec{}, s.TSStoreServers...), spec.TSStoreServers...),
+		Monitors:         append(append([]*TSServerSpec{}, s.Monitors...), spec.Monitors...),
+		Grafanas:         append(append([]*GrafanaSpec{}, s.Grafanas...), spec.Grafanas...),
+	}
+}
+
+// RemoveInstances returns a new Specification without the instances of the IDs
+func (s *Specification) RemoveInstances(ids set.StringSet) *Specification {
+	id := func(host string, port int) string {
+		return fmt.Sprintf("%s:%d", host, port)
+	}
+	ns := s.NewPart().(*Specification)
+	for _, srv := range s.TSMetaServers {
+		if !ids.Exist(id(srv.Host, srv.ClientPort)) {
+			ns.TSMetaServers = append(ns.TSMetaServers, srv)
+		}
+	}
+	for _, srv := range s.TSSqlServers {
+		if !ids.Exist(id(srv.Host, srv.Port)) {
+			ns.TSSqlServers = append(ns.TSSqlServers, srv)
+		}
+	}
+	for _, srv := range s.TSStoreServers {
+		if !ids.Exist(id(srv.Host, srv.SelectPort)) {
+			ns.TSStoreServers = append(ns.TSStoreServers, srv)
+		}
+	}
+	for _, srv := range s.Monitors {
+		if !ids.Exist(id(srv.Host, srv.Port)) {
+			ns.Monitors = append(ns.Monitors, srv)
+		}
+	}
+	for _, srv := range s.Grafanas {
+		if !ids.Exist(id(srv.Host, srv.Port)) {
+			ns.Grafanas = append(ns.Grafanas, srv)
+		}
+	}
+	return ns
+}
+
 // ComponentsByStopOrder return component in the order need to stop.
 func (s *Specification) ComponentsByStopOrder() (comps []Component) {
 	comps = s.ComponentsByStartOrder()
@@ -35,6 +35,8 @@ var (
 	ErrSaveMetaFailed = errNS.NewType("save_meta_failed")
 	// ErrSaveScaleOutFileFailed is ErrSaveMetaFailed
 	ErrSaveScaleOutFileFailed = errNS.NewType("save_scale-out_lock_failed")
+	// ErrScaleOutLocked is ErrScaleOutLocked
+	ErrScaleOutLocked = errNS.NewType("scale-out_locked")
 )
 
 const (
@@ -42,6 +44,8 @@ const (
 	metaFileName = "meta.yaml"
 	// BackupDirName is the directory to save backup files.
 	BackupDirName = "backup"
+	// ScaleOutLockName scale_out snapshot file, like file lock
+	ScaleOutLockName = ".scale-out.yaml"
 )
 
 // SpecManager control management of spec meta data.
@@ -139,6 +143,54 @@ func (s *SpecManager) Remove(clusterName string) error {
 	return os.RemoveAll(s.Path(clusterName))
 }
 
+// ScaleOutLockedErr returns an error if the scale-out file lock of the cluster exists
+func (s *SpecManager) ScaleOutLockedErr(clusterName string) error {
+	if locked := s.IsScaleOutLocked(clusterName); locked {
+		return ErrScaleOutLocked.New("Scale-out file lock already exists").
+			WithProperty(gui.SuggestionFromFormat(
+				"A previous scale-out of the cluster is not finished, the new instances are recorded in %s.\n"+
+					"Please clean up these instances and remove the lock file before running other operations.",
+				s.Path(clusterName, ScaleOutLockName)))
+	}
+	return nil
+}
+
+// IsScaleOutLocked checks whether the scale-out file lock of the cluster exists
+func (s *SpecManager) IsScaleOutLocked(clusterName string) (locked bool) {
+	_, err := os.Stat(s.Path(clusterName, ScaleOutLockName))
+	return err == nil
+}
+
+// NewScaleOutLock saves the topology to be scaled out as the scale-out file lock
+func (s *SpecManager) NewScaleOutLock(clusterName string, topo Topology) error {
+	wrapError := func(err error) *errorx.Error {
+		return ErrSaveScaleOutFileFailed.Wrap(err, "Failed to create scale-out file lock")
+	}
+
+	if locked := s.IsScaleOutLocked(clusterName); locked {
+		return s.ScaleOutLockedErr(clusterName)
+	}
+
+	if err := s.ensureDir(clusterName); err != nil {
+		return wrapError(err)
+	}
+
+	data, err := yaml.Marshal(topo)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if err := utils.WriteFile(s.Path(clusterName, ScaleOutLockName), data, 0644); err != nil {
+		return wrapError(err)
+	}
+	return nil
+}
+
+// ReleaseScaleOutLock removes the scale-out file lock of the cluster
+func (s *SpecManager) ReleaseScaleOutLock(clusterName string) error {
+	return os.Remove(s.Path(clusterName, ScaleOutLockName))
+}
+
 // List return the cluster names.
 func (s *SpecManager) List() (clusterNames []string, err error) {
 	fileInfos, err := os.ReadDir(s.base)
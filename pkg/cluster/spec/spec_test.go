@@ -15,9 +15,18 @@
 package spec
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/openGemini/gemix/pkg/meta"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
 )
@@ -56,3 +65,167 @@ ts_sql_servers:
 	assert.Equal(t, filepath.Join("test-deploy", "ts-sql-8086"), topo.TSSqlServers[0].DeployDir)
 	assert.Equal(t, filepath.Join("logs"), topo.TSSqlServers[0].LogDir)
 }
+
+func TestMergeTopology(t *testing.T) {
+	topo := Specification{}
+	err := yaml.Unmarshal([]byte(`
+global:
+  user: "test1"
+ts_meta_servers:
+  - host: 172.16.5.138
+ts_store_servers:
+  - host: 172.16.5.53
+ts_sql_servers:
+  - host: 172.16.5.233
+`), &topo)
+	assert.NoError(t, err)
+
+	newPart := topo.NewPart().(*Specification)
+	err = yaml.Unmarshal([]byte(`
+ts_store_servers:
+  - host: 172.16.5.54
+`), newPart)
+	assert.NoError(t, err)
+	assert.Equal(t, "test1", newPart.GlobalOptions.User)
+	assert.Len(t, newPart.TSMetaServers, 0)
+
+	merged := topo.Merge(newPart).(*Specification)
+	assert.Len(t, merged.TSMetaServers, 1)
+	assert.Len(t, merged.TSSqlServers, 1)
+	assert.Len(t, merged.TSStoreServers, 2)
+	assert.Equal(t, "172.16.5.54", merged.TSStoreServers[1].Host)
+	assert.Len(t, topo.TSStoreServers, 1)
+	assert.NoError(t, merged.Validate())
+}
+
+func TestValidateSpecDiff(t *testing.T) {
+	parse := func(s string) *Specification {
+		topo := &Specification{}
+		assert.NoError(t, yaml.Unmarshal([]byte(s), topo))
+		return topo
+	}
+	orig := `
+global:
+  user: "test1"
+server_configs:
+  ts-meta:
+    logging.level: info
+ts_meta_servers:
+  - host: 172.16.5.138
+ts_store_servers:
+  - host: 172.16.5.53
+ts_sql_servers:
+  - host: 172.16.5.233
+`
+	// editable and ignored fields
+	assert.NoError(t, ValidateSpecDiff(parse(orig), parse(`
+global:
+  user: "test1"
+  ssh_port: 220
+  resource_control:
+    memory_limit: 4G
+server_configs:
+  ts-meta:
+    logging.level: debug
+ts_meta_servers:
+  - host: 172.16.5.138
+    config:
+      logging.level: warn
+ts_store_servers:
+  - host: 172.16.5.53
+ts_sql_servers:
+  - host: 172.16.5.233
+`)))
+
+	// immutable fields
+	err := ValidateSpecDiff(parse(orig), parse(`
+global:
+  user: "test2"
+ts_meta_servers:
+  - host: 172.16.5.138
+    client_port: 8191
+ts_store_servers:
+  - host: 172.16.5.53
+  - host: 172.16.5.54
+ts_sql_servers:
+  - host: 172.16.5.233
+`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "`global.user` changed from 'test1' to 'test2'")
+	assert.Contains(t, err.Error(), "`ts_meta_servers.0.client_port`")
+	assert.Contains(t, err.Error(), "`ts_store_servers` has 2 items")
+}
+
+func TestSetTLSConfig(t *testing.T) {
+	topo := Specification{}
+	err := yaml.Unmarshal([]byte(`
+global:
+  user: "test1"
+  enable_tls: true
+ts_meta_servers:
+  - host: 172.16.5.138
+ts_store_servers:
+  - host: 172.16.5.53
+ts_sql_servers:
+  - host: 172.16.5.233
+`), &topo)
+	assert.NoError(t, err)
+	assert.NoError(t, topo.Validate())
+
+	paths := meta.DirPaths{Deploy: "/home/test1/ts-sql-8086"}
+	ins := (&TSSqlComponent{Topology: &topo}).Instances()[0].(*TSSqlInstance)
+	configs, err := ins.setTLSConfig(context.Background(), true, nil, paths)
+	assert.NoError(t, err)
+	assert.Equal(t, true, configs["spdy.tls-enable"])
+	assert.Equal(t, true, configs["http.https-enabled"])
+	assert.Equal(t, "/home/test1/ts-sql-8086/tls/ts-sql.crt", configs["http.https-certificate"])
+	assert.Equal(t, "/home/test1/ts-sql-8086/tls/ts-sql.pem", configs["spdy.tls-private-key"])
+	assert.Equal(t, "/home/test1/ts-sql-8086/tls/ca.crt", configs["spdy.tls-ca-root"])
+
+	// the keys are removed after TLS is disabled
+	configs, err = ins.setTLSConfig(context.Background(), false, configs, paths)
+	assert.NoError(t, err)
+	assert.Empty(t, configs)
+}
+
+func TestTSMetaStatus(t *testing.T) {
+	var leader string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ping":
+			w.WriteHeader(http.StatusNoContent)
+		case "/status":
+			fmt.Fprintf(w, `{"nodeType":"meta","leader":"%s"}`, leader)
+		}
+	}))
+	defer srv.Close()
+
+	host, port, err := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+	assert.NoError(t, err)
+	clientPort, _ := strconv.Atoi(port)
+	s := &TSMetaSpec{Host: host, ClientPort: clientPort, RaftPort: 8088}
+
+	leader = net.JoinHostPort(host, "8088")
+	assert.Equal(t, "Up|L", s.Status(context.Background(), time.Second, nil))
+	leader = "172.16.5.138:8088"
+	assert.Equal(t, "Up", s.Status(context.Background(), time.Second, nil))
+
+	srv.Close()
+	assert.Equal(t, "Down", s.Status(context.Background(), time.Second, nil))
+}
+
+func TestTSStoreIsAddr(t *testing.T) {
+	s := &TSStoreSpec{Host: "172.16.5.140", ManageHost: "10.0.0.140", IngestPort: 8400, SelectPort: 8401}
+	cases := map[string]bool{
+		"172.16.5.140:8400": true,
+		"172.16.5.140:8401": true,
+		"10.0.0.140:8401":   true,
+		"172.16.5.140:9400": false,
+		"172.16.5.141:8400": false,
+		"172.16.5.140":      false,
+		"":                  false,
+	}
+	for addr, expected := range cases {
+		assert.Equal(t, expected, s.IsAddr(addr), addr)
+	}
+}
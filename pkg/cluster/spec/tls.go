@@ -0,0 +1,86 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spec
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// the names of the certificates and keys of the TLS enabled cluster
+const (
+	// TLSCertKeyDir is the directory to save the certificates and keys, in both
+	// the cluster profile dir and the deploy dir of each instance
+	TLSCertKeyDir = "tls"
+	// TLSCACert is the CA certificate of the cluster
+	TLSCACert = "ca.crt"
+	// TLSCAKey is the private key of the CA, it's only saved in the cluster profile dir
+	TLSCAKey = "ca.pem"
+	// TLSClientCert is the client certificate used by gemix to access the cluster
+	TLSClientCert = "client.crt"
+	// TLSClientKey is the private key of the client certificate
+	TLSClientKey = "client.pem"
+)
+
+// TLSConfig generates a tls.Config for the specification as needed, nil is returned if TLS is not enabled.
+func (s *Specification) TLSConfig(dir string) (*tls.Config, error) {
+	if !s.GlobalOptions.TLSEnabled {
+		return nil, nil
+	}
+	return LoadClientCert(dir)
+}
+
+// LoadClientCert reads the client certificate and the CA certificate in the dir
+// and returns a tls.Config to access the components of the cluster.
+func LoadClientCert(dir string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, TLSClientCert), filepath.Join(dir, TLSClientKey))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to load the client certificate")
+	}
+
+	caCert, err := os.ReadFile(filepath.Join(dir, TLSCACert))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to load the CA certificate")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, errors.New("failed to parse the CA certificate")
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// TLSCertPath returns the path of the certificate of the component in the tls dir of the instance
+func TLSCertPath(tlsDir, comp string) string {
+	return filepath.Join(tlsDir, TLSCertKeyDir, comp+".crt")
+}
+
+// TLSKeyPath returns the path of the private key of the component in the tls dir of the instance
+func TLSKeyPath(tlsDir, comp string) string {
+	return filepath.Join(tlsDir, TLSCertKeyDir, comp+".pem")
+}
+
+// TLSCAPath returns the path of the CA certificate in the tls dir of the instance
+func TLSCAPath(tlsDir string) string {
+	return filepath.Join(tlsDir, TLSCertKeyDir, TLSCACert)
+}
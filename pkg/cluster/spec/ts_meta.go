@@ -18,9 +18,12 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"path/filepath"
+	"strconv"
 	"time"
 
+	"github.com/openGemini/gemix/pkg/cluster/api"
 	"github.com/openGemini/gemix/pkg/cluster/ctxt"
 	"github.com/openGemini/gemix/pkg/cluster/template/scripts"
 	"github.com/openGemini/gemix/pkg/meta"
@@ -33,7 +36,8 @@ type TSMetaSpec struct {
 	Arch string `yaml:"arch,omitempty"`
 	OS   string `yaml:"os,omitempty"`
 
-	Source string `yaml:"source,omitempty" validate:"source:editable"`
+	Source  string `yaml:"source,omitempty" validate:"source:editable"`
+	Patched bool   `yaml:"patched,omitempty"`
 
 	// Use Name to get the name with a default value if it's empty.
 	Name string `yaml:"name"`
@@ -56,31 +60,43 @@ type TSMetaSpec struct {
 	Config map[string]any `yaml:"config,omitempty" validate:"config:ignore"`
 }
 
-// Status queries current status of the instance
+// Status queries current status of the instance, the raft leader is marked as `Up|L`
 func (s *TSMetaSpec) Status(ctx context.Context, timeout time.Duration, tlsCfg *tls.Config, _ ...string) string {
-	//if timeout < time.Second {
-	//	timeout = statusQueryTimeout
-	//}
-
-	//addr := utils.JoinHostPort(s.GetManageHost(), s.ClientPort)
-	//pc := api.NewPDClient(ctx, []string{addr}, timeout, tlsCfg)
-	//
-	//// check health
-	//err := pc.CheckHealth()
-	//if err != nil {
-	//	return "Down"
-	//}
-	//
-	//// find leader node
-	//leader, err := pc.GetLeader()
-	//if err != nil {
-	//	return "ERR"
-	//}
-	res := "Up"
-	//if s.Name == leader.Name {
-	//	res += "|L"
-	//}
-	return res
+	if timeout < time.Second {
+		timeout = statusQueryTimeout
+	}
+
+	client := api.NewTSMetaClient(ctx, []string{utils.JoinHostPort(s.GetManageHost(), s.ClientPort)}, timeout, tlsCfg)
+	if err := client.Ping(); err != nil {
+		return "Down"
+	}
+	status, err := client.GetStatus()
+	if err != nil {
+		// the node is healthy, but the raft status is unknown
+		return "Up"
+	}
+	if s.isAddr(status.Leader) {
+		return "Up|L"
+	}
+	return "Up"
+}
+
+// isAddr checks whether the address is served by the instance
+func (s *TSMetaSpec) isAddr(addr string) bool {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host != s.Host && host != s.GetManageHost() {
+		return false
+	}
+	p, _ := strconv.Atoi(port)
+	return p == s.ClientPort || p == s.PeerPort || p == s.RaftPort
+}
+
+// Uptime queries current uptime of the instance
+func (s *TSMetaSpec) Uptime(ctx context.Context, timeout time.Duration, _ *tls.Config) time.Duration {
+	return UptimeByService(ctx, s.GetManageHost(), serviceName(ComponentTSMeta, s.ClientPort), timeout)
 }
 
 func (s *TSMetaSpec) SSH() (string, int) {
@@ -154,9 +170,7 @@ func (c *TSMetaComponent) Instances() []Instance {
 					s.DataDir,
 				},
 				StatusFn: s.Status,
-				UptimeFn: func(_ context.Context, timeout time.Duration, tlsCfg *tls.Config) time.Duration {
-					return UptimeByHost(s.GetManageHost(), s.ClientPort, timeout, tlsCfg)
-				},
+				UptimeFn: s.Uptime,
 			},
 			topo: c.Topology,
 		})
@@ -171,6 +185,18 @@ type TSMetaInstance struct {
 	topo *Specification
 }
 
+// Ready implements Instance interface, the https api is checked as well in TLS enabled cluster
+func (i *TSMetaInstance) Ready(ctx context.Context, e ctxt.Executor, timeout uint64, tlsCfg *tls.Config) error {
+	if err := i.BaseInstance.Ready(ctx, e, timeout, tlsCfg); err != nil {
+		return err
+	}
+	if tlsCfg == nil {
+		return nil
+	}
+	addr := utils.JoinHostPort(i.GetManageHost(), i.InstanceSpec.(*TSMetaSpec).ClientPort)
+	return api.NewTSMetaClient(ctx, []string{addr}, time.Second, tlsCfg).WaitReady(readyTimeout(timeout))
+}
+
 func (i *TSMetaInstance) InitConfig(ctx context.Context, e ctxt.Executor, clusterName string, clusterVersion string, deployUser string, paths meta.DirPaths) error {
 	topo := i.topo
 	if err := i.BaseInstance.InitConfig(ctx, e, topo.GlobalOptions, deployUser, paths); err != nil {
@@ -198,14 +224,13 @@ func (i *TSMetaInstance) InitConfig(ctx context.Context, e ctxt.Executor, cluste
 
 	globalConfig := topo.ServerConfigs.TsMeta
 
-	// set TLS configs
-	//spec.Config, err = i.setTLSConfig(ctx, enableTLS, spec.Config, paths)
-	//if err != nil {
-	//	return err
-	//}
-
 	configs := i.SetDefaultConfig(spec.Config)
 
+	// set TLS configs
+	if configs, err = i.setTLSConfig(ctx, topo.GlobalOptions.TLSEnabled, configs, paths); err != nil {
+		return err
+	}
+
 	if err = i.MergeServerConfig(ctx, e, globalConfig, configs, paths); err != nil {
 		return errors.WithStack(err)
 	}
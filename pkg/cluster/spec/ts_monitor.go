@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/openGemini/gemix/pkg/cluster/ctxt"
 	"github.com/openGemini/gemix/pkg/cluster/template/scripts"
@@ -62,6 +63,17 @@ type TSMonitorSpec struct {
 	Config         map[string]any `yaml:"config,omitempty" validate:"config:ignore"`
 }
 
+// Status queries current status of the instance, ts-monitor listens on no port,
+// so the systemd service is checked instead
+func (s *TSMonitorSpec) Status(ctx context.Context, timeout time.Duration, _ *tls.Config, _ ...string) string {
+	return statusByService(ctx, s.GetManageHost(), serviceName(ComponentTSMonitor, 0), timeout)
+}
+
+// Uptime queries current uptime of the instance
+func (s *TSMonitorSpec) Uptime(ctx context.Context, timeout time.Duration, _ *tls.Config) time.Duration {
+	return UptimeByService(ctx, s.GetManageHost(), serviceName(ComponentTSMonitor, 0), timeout)
+}
+
 func (s *TSMonitorSpec) SSH() (string, int) {
 	host := s.Host
 	if s.ManageHost != "" {
@@ -118,10 +130,12 @@ func (c *TSMonitorComponent) Instances() []Instance {
 			continue
 		}
 		ms := &TSMonitorSpec{
-			OS:        s.OS,
-			Arch:      s.Arch,
-			DeployDir: c.Topology.MonitoredOptions.DeployDir,
-			LogDir:    c.Topology.MonitoredOptions.LogDir,
+			OS:         s.OS,
+			Arch:       s.Arch,
+			Host:       s.Host,
+			ManageHost: s.ManageHost,
+			DeployDir:  c.Topology.MonitoredOptions.DeployDir,
+			LogDir:     c.Topology.MonitoredOptions.LogDir,
 			MonitorProcess: map[string]struct{}{
 				"ts-store": {},
 			},
@@ -148,6 +162,8 @@ func (c *TSMonitorComponent) Instances() []Instance {
 					s.DeployDir,
 					s.LogDir,
 				},
+				StatusFn: ms.Status,
+				UptimeFn: ms.Uptime,
 			},
 			topo: c.Topology,
 		})
@@ -163,10 +179,12 @@ func (c *TSMonitorComponent) Instances() []Instance {
 			continue
 		}
 		ms := &TSMonitorSpec{
-			OS:        s.OS,
-			Arch:      s.Arch,
-			DeployDir: c.Topology.MonitoredOptions.DeployDir,
-			LogDir:    c.Topology.MonitoredOptions.LogDir,
+			OS:         s.OS,
+			Arch:       s.Arch,
+			Host:       s.Host,
+			ManageHost: s.ManageHost,
+			DeployDir:  c.Topology.MonitoredOptions.DeployDir,
+			LogDir:     c.Topology.MonitoredOptions.LogDir,
 			MonitorProcess: map[string]struct{}{
 				"ts-meta": {},
 			},
@@ -191,6 +209,8 @@ func (c *TSMonitorComponent) Instances() []Instance {
 					s.DeployDir,
 					s.LogDir,
 				},
+				StatusFn: ms.Status,
+				UptimeFn: ms.Uptime,
 			},
 			topo: c.Topology,
 		})
@@ -205,10 +225,12 @@ func (c *TSMonitorComponent) Instances() []Instance {
 			continue
 		}
 		ms := &TSMonitorSpec{
-			OS:        s.OS,
-			Arch:      s.Arch,
-			DeployDir: c.Topology.MonitoredOptions.DeployDir,
-			LogDir:    c.Topology.MonitoredOptions.LogDir,
+			OS:         s.OS,
+			Arch:       s.Arch,
+			Host:       s.Host,
+			ManageHost: s.ManageHost,
+			DeployDir:  c.Topology.MonitoredOptions.DeployDir,
+			LogDir:     c.Topology.MonitoredOptions.LogDir,
 			MonitorProcess: map[string]struct{}{
 				"ts-sql": {},
 			},
@@ -233,6 +255,8 @@ func (c *TSMonitorComponent) Instances() []Instance {
 					s.DeployDir,
 					s.LogDir,
 				},
+				StatusFn: ms.Status,
+				UptimeFn: ms.Uptime,
 			},
 			topo: c.Topology,
 		})
@@ -16,9 +16,12 @@ package spec
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"path/filepath"
+	"time"
 
+	"github.com/openGemini/gemix/pkg/cluster/api"
 	"github.com/openGemini/gemix/pkg/cluster/ctxt"
 	"github.com/openGemini/gemix/pkg/cluster/template/scripts"
 	"github.com/openGemini/gemix/pkg/meta"
@@ -31,7 +34,8 @@ type TSSqlSpec struct {
 	Arch string `yaml:"arch,omitempty"`
 	OS   string `yaml:"os,omitempty"`
 
-	Source string `yaml:"source,omitempty" validate:"source:editable"`
+	Source  string `yaml:"source,omitempty" validate:"source:editable"`
+	Patched bool   `yaml:"patched,omitempty"`
 
 	// Use Name to get the name with a default value if it's empty.
 	Name           string `yaml:"name"`
@@ -52,6 +56,16 @@ type TSSqlSpec struct {
 	Config map[string]any `yaml:"config,omitempty" validate:"config:ignore"`
 }
 
+// Status queries current status of the instance
+func (s *TSSqlSpec) Status(ctx context.Context, timeout time.Duration, tlsCfg *tls.Config, _ ...string) string {
+	return statusByHost(s.GetManageHost(), s.Port, "/ping", timeout, tlsCfg)
+}
+
+// Uptime queries current uptime of the instance
+func (s *TSSqlSpec) Uptime(ctx context.Context, timeout time.Duration, _ *tls.Config) time.Duration {
+	return UptimeByService(ctx, s.GetManageHost(), serviceName(ComponentTSSql, s.Port), timeout)
+}
+
 func (s *TSSqlSpec) SSH() (string, int) {
 	host := s.Host
 	if s.ManageHost != "" {
@@ -124,10 +138,8 @@ func (c *TSSqlComponent) Instances() []Instance {
 					s.DeployDir,
 					s.LogDir,
 				},
-				//StatusFn: s.Status,
-				//UptimeFn: func(_ context.Context, timeout time.Duration, tlsCfg *tls.Config) time.Duration {
-				//	return UptimeByHost(s.GetManageHost(), s.ClientPort, timeout, tlsCfg)
-				//},
+				StatusFn: s.Status,
+				UptimeFn: s.Uptime,
 			},
 			topo: c.Topology,
 		})
@@ -142,6 +154,18 @@ type TSSqlInstance struct {
 	topo *Specification
 }
 
+// Ready implements Instance interface, the https api is checked as well in TLS enabled cluster
+func (i *TSSqlInstance) Ready(ctx context.Context, e ctxt.Executor, timeout uint64, tlsCfg *tls.Config) error {
+	if err := i.BaseInstance.Ready(ctx, e, timeout, tlsCfg); err != nil {
+		return err
+	}
+	if tlsCfg == nil {
+		return nil
+	}
+	addr := utils.JoinHostPort(i.GetManageHost(), i.InstanceSpec.(*TSSqlSpec).Port)
+	return api.NewTSSqlClient(ctx, []string{addr}, time.Second, tlsCfg).WaitReady(readyTimeout(timeout))
+}
+
 func (i *TSSqlInstance) InitConfig(ctx context.Context, e ctxt.Executor, clusterName string, clusterVersion string, deployUser string, paths meta.DirPaths) error {
 	topo := i.topo
 	if err := i.BaseInstance.InitConfig(ctx, e, topo.GlobalOptions, deployUser, paths); err != nil {
@@ -170,14 +194,13 @@ func (i *TSSqlInstance) InitConfig(ctx context.Context, e ctxt.Executor, cluster
 
 	globalConfig := topo.ServerConfigs.TsSql
 
-	// set TLS configs
-	//spec.Config, err = i.setTLSConfig(ctx, enableTLS, spec.Config, paths)
-	//if err != nil {
-	//	return errors.WithStack(err)
-	//}
-
 	configs := i.SetDefaultConfig(spec.Config)
 
+	// set TLS configs
+	if configs, err = i.setTLSConfig(ctx, topo.GlobalOptions.TLSEnabled, configs, paths); err != nil {
+		return err
+	}
+
 	if err = i.MergeServerConfig(ctx, e, globalConfig, configs, paths); err != nil {
 		return errors.WithStack(err)
 	}
@@ -16,9 +16,15 @@ package spec
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/openGemini/gemix/pkg/cluster/api"
 	"github.com/openGemini/gemix/pkg/cluster/ctxt"
 	"github.com/openGemini/gemix/pkg/cluster/template/scripts"
 	"github.com/openGemini/gemix/pkg/meta"
@@ -31,7 +37,8 @@ type TSStoreSpec struct {
 	Arch string `yaml:"arch,omitempty"`
 	OS   string `yaml:"os,omitempty"`
 
-	Source string `yaml:"source,omitempty" validate:"source:editable"`
+	Source  string `yaml:"source,omitempty" validate:"source:editable"`
+	Patched bool   `yaml:"patched,omitempty"`
 
 	// Use Name to get the name with a default value if it's empty.
 	Name string `yaml:"name"`
@@ -53,6 +60,53 @@ type TSStoreSpec struct {
 	Config map[string]any `yaml:"config,omitempty" validate:"config:ignore"`
 }
 
+// Status queries current status of the instance, ts-store has no http api, so the
+// select port is probed and the status of the node is derived from ts-meta
+func (s *TSStoreSpec) Status(ctx context.Context, timeout time.Duration, tlsCfg *tls.Config, tsMetaList ...string) string {
+	status := statusByPort(s.GetManageHost(), s.SelectPort, timeout)
+	if status != "Up" || len(tsMetaList) == 0 {
+		return status
+	}
+
+	if timeout < time.Second {
+		timeout = statusQueryTimeout
+	}
+	stores, err := api.NewTSMetaClient(ctx, tsMetaList, timeout, tlsCfg).GetStores()
+	if err != nil {
+		// the port is listened, but ts-meta is not available
+		return status
+	}
+	for _, store := range stores {
+		if !s.IsAddr(store.Host) && !s.IsAddr(store.TCPHost) {
+			continue
+		}
+		if strings.EqualFold(store.Status, "alive") {
+			return "Up"
+		}
+		return "Offline"
+	}
+	// the node is not registered in ts-meta
+	return "Offline"
+}
+
+// IsAddr checks whether the address is served by the instance
+func (s *TSStoreSpec) IsAddr(addr string) bool {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host != s.Host && host != s.GetManageHost() {
+		return false
+	}
+	p, _ := strconv.Atoi(port)
+	return p == s.IngestPort || p == s.SelectPort
+}
+
+// Uptime queries current uptime of the instance
+func (s *TSStoreSpec) Uptime(ctx context.Context, timeout time.Duration, _ *tls.Config) time.Duration {
+	return UptimeByService(ctx, s.GetManageHost(), serviceName(ComponentTSStore, s.SelectPort), timeout)
+}
+
 func (s *TSStoreSpec) SSH() (string, int) {
 	host := s.Host
 	if s.ManageHost != "" {
@@ -122,10 +176,8 @@ func (c *TSStoreComponent) Instances() []Instance {
 					s.LogDir,
 					s.DataDir,
 				},
-				//StatusFn: s.Status,
-				//UptimeFn: func(_ context.Context, timeout time.Duration, tlsCfg *tls.Config) time.Duration {
-				//	return UptimeByHost(s.GetManageHost(), s.ClientPort, timeout, tlsCfg)
-				//},
+				StatusFn: s.Status,
+				UptimeFn: s.Uptime,
 			},
 			topo: c.Topology,
 		})
@@ -140,13 +192,17 @@ type TSStoreInstance struct {
 	topo *Specification
 }
 
+// IsAddr checks whether the address registered in ts-meta is served by the instance
+func (i *TSStoreInstance) IsAddr(addr string) bool {
+	return i.InstanceSpec.(*TSStoreSpec).IsAddr(addr)
+}
+
 func (i *TSStoreInstance) InitConfig(ctx context.Context, e ctxt.Executor, clusterName string, clusterVersion string, deployUser string, paths meta.DirPaths) error {
 	topo := i.topo
 	if err := i.BaseInstance.InitConfig(ctx, e, topo.GlobalOptions, deployUser, paths); err != nil {
 		return err
 	}
 
-	//enableTLS := topo.GlobalOptions.TLSEnabled
 	spec := i.InstanceSpec.(*TSStoreSpec)
 
 	cfg := &scripts.TSStoreScript{
@@ -171,14 +227,13 @@ func (i *TSStoreInstance) InitConfig(ctx context.Context, e ctxt.Executor, clust
 
 	globalConfig := topo.ServerConfigs.TsStore
 
-	// set TLS configs
-	//spec.Config, err = i.setTLSConfig(ctx, enableTLS, spec.Config, paths)
-	//if err != nil {
-	//	return err
-	//}
-
 	configs := i.SetDefaultConfig(spec.Config)
 
+	// set TLS configs
+	if configs, err = i.setTLSConfig(ctx, topo.GlobalOptions.TLSEnabled, configs, paths); err != nil {
+		return err
+	}
+
 	if err = i.MergeServerConfig(ctx, e, globalConfig, configs, paths); err != nil {
 		return errors.WithStack(err)
 	}
@@ -18,8 +18,12 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
 	"github.com/openGemini/gemix/pkg/utils"
 )
 
@@ -53,7 +57,75 @@ func statusByHost(host string, port int, path string, timeout time.Duration, tls
 	return "Up"
 }
 
-// UptimeByHost queries current uptime of the instance by http Prometheus metric api.
-func UptimeByHost(host string, port int, timeout time.Duration, tlsCfg *tls.Config) time.Duration {
-	return 0
+// readyTimeout returns the timeout to wait for the api of the instance, the
+// timeout is in seconds and the default one is used if it's not specified
+func readyTimeout(timeout uint64) time.Duration {
+	if timeout == 0 {
+		return statusQueryTimeout
+	}
+	return time.Second * time.Duration(timeout)
+}
+
+// statusByPort queries current status of the instance by dialing its tcp port,
+// it's used by the components which do not expose any http api.
+func statusByPort(host string, port int, timeout time.Duration) string {
+	if timeout < time.Second {
+		timeout = statusQueryTimeout
+	}
+
+	conn, err := net.DialTimeout("tcp", utils.JoinHostPort(host, port), timeout)
+	if err != nil {
+		return "Down"
+	}
+	_ = conn.Close()
+	return "Up"
+}
+
+// statusByService queries current status of the systemd service on the host,
+// the executor of the host must have been set in ctx, otherwise "N/A" is returned.
+func statusByService(ctx context.Context, host, service string, timeout time.Duration) string {
+	e, ok := ctxt.GetInner(ctx).GetExecutor(host)
+	if !ok {
+		return "N/A"
+	}
+	if timeout < time.Second {
+		timeout = statusQueryTimeout
+	}
+
+	stdout, _, err := e.Execute(ctx, fmt.Sprintf("systemctl is-active %s", service), false, timeout)
+	if err != nil || strings.TrimSpace(string(stdout)) != "active" {
+		return "Down"
+	}
+	return "Up"
+}
+
+// UptimeByService queries current uptime of the systemd service on the host by
+// the elapsed time of its main process, the executor of the host must have been set in ctx.
+func UptimeByService(ctx context.Context, host, service string, timeout time.Duration) time.Duration {
+	e, ok := ctxt.GetInner(ctx).GetExecutor(host)
+	if !ok {
+		return 0
+	}
+	if timeout < time.Second {
+		timeout = statusQueryTimeout
+	}
+
+	cmd := fmt.Sprintf("ps -o etimes= -p $(systemctl show -p MainPID %s | cut -d= -f2)", service)
+	stdout, _, err := e.Execute(ctx, cmd, false, timeout)
+	if err != nil {
+		return 0
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(string(stdout)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// serviceName returns the systemd service name of the component instance
+func serviceName(comp string, port int) string {
+	if port > 0 {
+		return fmt.Sprintf("%s-%d.service", comp, port)
+	}
+	return fmt.Sprintf("%s.service", comp)
 }
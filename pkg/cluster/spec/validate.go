@@ -53,6 +53,13 @@ var (
 		`followed by lower case letters, digits, underscores, or dashes. ` +
 		`Usernames may only be up to 32 characters long. ` +
 		`Groupnames may only be up to 16 characters long.`)
+	errEditImmutableField = errNSDeploy.NewType("immutable_field", utils.ErrTraitPreCheck)
+)
+
+const (
+	validateTagName     = "validate"
+	validateTagEditable = "editable"
+	validateTagIgnore   = "ignore"
 )
 
 // Linux username and groupname must start with a lower case letter or an underscore,
@@ -713,9 +720,14 @@ func (s *Specification) validateTLSEnabled() error {
 		}
 	})
 
+	// the monitoring components are kept serving plain http in TLS enabled cluster
 	for _, c := range compList {
 		switch c.Name() {
-		case ComponentOpenGemini,
+		case ComponentTSMeta,
+			ComponentTSSql,
+			ComponentTSStore,
+			ComponentTSServer,
+			ComponentTSMonitor,
 			ComponentGrafana:
 		default:
 			return errors.Errorf("component %s is not supported in TLS enabled cluster", c.Name())
@@ -865,3 +877,88 @@ func RelativePathDetect(topo any, isSkipField func(reflect.Value) bool) error {
 
 	return nil
 }
+
+// ValidateSpecDiff checks that only the fields marked as `editable` or `ignore` by the
+// validate tag are changed in the new topology, the instances can't be added or removed.
+func ValidateSpecDiff(s1, s2 Topology) error {
+	var changes []string
+	diffSpecValue("", reflect.ValueOf(s1), reflect.ValueOf(s2), &changes)
+	if len(changes) == 0 {
+		return nil
+	}
+	return errEditImmutableField.New("immutable fields changed:\n  %s", strings.Join(changes, "\n  ")).
+		WithProperty(gui.SuggestionFromString(
+			"Please revert the changes above, use `scale-out` or `scale-in` to add or remove instances."))
+}
+
+// diffSpecValue collects the changed fields which are not editable
+func diffSpecValue(path string, v1, v2 reflect.Value, changes *[]string) {
+	if v1.Kind() == reflect.Ptr || v1.Kind() == reflect.Interface {
+		if v1.IsNil() || v2.IsNil() {
+			if v1.IsNil() != v2.IsNil() {
+				*changes = append(*changes, fmt.Sprintf("`%s` is added or removed", path))
+			}
+			return
+		}
+		diffSpecValue(path, v1.Elem(), v2.Elem(), changes)
+		return
+	}
+
+	switch v1.Kind() {
+	case reflect.Struct:
+		t := v1.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, ctl := parseValidateTag(field)
+			if ctl == validateTagIgnore {
+				continue
+			}
+			// the leaf field marked as editable can be modified, the struct field
+			// marked as editable still checks its own fields
+			if ctl == validateTagEditable && !isStructType(field.Type) {
+				continue
+			}
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			diffSpecValue(fieldPath, v1.Field(i), v2.Field(i), changes)
+		}
+	case reflect.Slice, reflect.Array:
+		if v1.Len() != v2.Len() {
+			*changes = append(*changes, fmt.Sprintf("`%s` has %d items, but %d items before", path, v2.Len(), v1.Len()))
+			return
+		}
+		for i := 0; i < v1.Len(); i++ {
+			diffSpecValue(fmt.Sprintf("%s.%d", path, i), v1.Index(i), v2.Index(i), changes)
+		}
+	default:
+		if !reflect.DeepEqual(v1.Interface(), v2.Interface()) {
+			*changes = append(*changes, fmt.Sprintf("`%s` changed from '%v' to '%v'", path, v1.Interface(), v2.Interface()))
+		}
+	}
+}
+
+// parseValidateTag returns the yaml name and the control of the validate tag of the field
+func parseValidateTag(field reflect.StructField) (name, ctl string) {
+	name = strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "" {
+		name = field.Name
+	}
+	if tag := field.Tag.Get(validateTagName); tag != "" {
+		if i := strings.LastIndex(tag, ":"); i >= 0 {
+			ctl = tag[i+1:]
+		}
+	}
+	return name, ctl
+}
+
+func isStructType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
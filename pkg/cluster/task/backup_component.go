@@ -0,0 +1,68 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	"github.com/pkg/errors"
+)
+
+// BackupComponent is used to copy the binaries and configs of the current version
+// of a component to the backup directories before it is replaced
+type BackupComponent struct {
+	component string
+	fromVer   string
+	host      string
+	deployDir string
+}
+
+// Execute implements the Task interface
+func (c *BackupComponent) Execute(ctx context.Context) error {
+	exec, found := ctxt.GetInner(ctx).GetExecutor(c.host)
+	if !found {
+		return ErrNoExecutor
+	}
+
+	// backup to bin.bak.<version> and conf.bak.<version>, the existing backups of the same
+	// version are kept, since the binaries may have been patched after the first backup
+	var cmd string
+	for _, dir := range []string{"bin", "conf"} {
+		if cmd != "" {
+			cmd += " && "
+		}
+		cmd += fmt.Sprintf(`(test ! -d %[1]s/%[2]s || test -d %[1]s/%[2]s.bak.%[3]s || cp -rp %[1]s/%[2]s %[1]s/%[2]s.bak.%[3]s)`,
+			c.deployDir, dir, c.fromVer)
+	}
+
+	_, stderr, err := exec.Execute(ctx, cmd, false)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to backup %s on %s, stderr: %s", c.component, c.host, string(stderr))
+	}
+	return nil
+}
+
+// Rollback implements the Task interface
+func (c *BackupComponent) Rollback(ctx context.Context) error {
+	return ErrUnsupportedRollback
+}
+
+// String implements the fmt.Stringer interface
+func (c *BackupComponent) String() string {
+	return fmt.Sprintf("BackupComponent: component=%s, currentVersion=%s, remote=%s:%s",
+		c.component, c.fromVer, c.host, c.deployDir)
+}
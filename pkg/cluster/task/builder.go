@@ -16,7 +16,9 @@ package task
 import (
 	"context"
 
+	"github.com/openGemini/gemix/pkg/cluster/executor"
 	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/crypto"
 	logprinter "github.com/openGemini/gemix/pkg/logger/printer"
 	"github.com/openGemini/gemix/pkg/meta"
 )
@@ -34,7 +36,7 @@ func NewBuilder(logger *logprinter.Logger) *Builder {
 
 // RootSSH appends a RootSSH task to the current task collection
 func (b *Builder) RootSSH(
-	host string, port int, user, password, keyFile, passphrase string, sshTimeout, exeTimeout uint64) *Builder {
+	host string, port int, user, password, keyFile, passphrase string, sshTimeout, exeTimeout uint64, sshType executor.SSHType) *Builder {
 	b.tasks = append(b.tasks, &RootSSH{
 		host:       host,
 		port:       port,
@@ -44,12 +46,13 @@ func (b *Builder) RootSSH(
 		passphrase: passphrase,
 		timeout:    sshTimeout,
 		exeTimeout: exeTimeout,
+		sshType:    sshType,
 	})
 	return b
 }
 
 // NewSimpleUerSSH  append a UserSSH task to the current task collection with operator.Options and SSHConnectionProps
-func NewSimpleUerSSH(logger *logprinter.Logger, host string, port int, user string, sshTimeout, exeTimeout uint64) *Builder {
+func NewSimpleUerSSH(logger *logprinter.Logger, host string, port int, user string, sshTimeout, exeTimeout uint64, sshType executor.SSHType) *Builder {
 	return NewBuilder(logger).
 		UserSSH(
 			host,
@@ -57,17 +60,19 @@ func NewSimpleUerSSH(logger *logprinter.Logger, host string, port int, user stri
 			user,
 			sshTimeout,
 			exeTimeout,
+			sshType,
 		)
 }
 
 // UserSSH append a UserSSH task to the current task collection
-func (b *Builder) UserSSH(host string, port int, deployUser string, sshTimeout, exeTimeout uint64) *Builder {
+func (b *Builder) UserSSH(host string, port int, deployUser string, sshTimeout, exeTimeout uint64, sshType executor.SSHType) *Builder {
 	b.tasks = append(b.tasks, &UserSSH{
 		host:       host,
 		port:       port,
 		deployUser: deployUser,
 		timeout:    sshTimeout,
 		exeTimeout: exeTimeout,
+		sshType:    sshType,
 	})
 	return b
 }
@@ -81,10 +86,20 @@ func (b *Builder) Func(name string, fn func(ctx context.Context) error) *Builder
 	return b
 }
 
+// FuncWithRollback append a Func task which is undone by rollback
+func (b *Builder) FuncWithRollback(name string, fn, rollback func(ctx context.Context) error) *Builder {
+	b.tasks = append(b.tasks, &Func{
+		name:     name,
+		fn:       fn,
+		rollback: rollback,
+	})
+	return b
+}
+
 // ClusterSSH init all UserSSH need for the cluster.
 func (b *Builder) ClusterSSH(
 	topo spec.Topology,
-	deployUser string, sshTimeout, exeTimeout uint64) *Builder {
+	deployUser string, sshTimeout, exeTimeout uint64, sshType executor.SSHType) *Builder {
 	var tasks []Task
 	topo.IterInstance(func(inst spec.Instance) {
 		tasks = append(tasks, &UserSSH{
@@ -93,6 +108,7 @@ func (b *Builder) ClusterSSH(
 			deployUser: deployUser,
 			timeout:    sshTimeout,
 			exeTimeout: exeTimeout,
+			sshType:    sshType,
 		})
 	})
 
@@ -107,7 +123,7 @@ func (b *Builder) Download(component, os, arch string, version string) *Builder
 }
 
 // InitConfig appends a CopyComponent task to the current task collection
-func (b *Builder) InitConfig(clusterName, clusterVersion string, specManager *spec.SpecManager, inst spec.Instance, deployUser string, ignoreCheck bool, paths meta.DirPaths) *Builder {
+func (b *Builder) InitConfig(clusterName, clusterVersion string, specManager *spec.SpecManager, inst spec.Instance, deployUser string, ignoreCheck bool, paths meta.DirPaths, backup bool) *Builder {
 
 	b.tasks = append(b.tasks, &InitConfig{
 		specManager:    specManager,
@@ -117,6 +133,7 @@ func (b *Builder) InitConfig(clusterName, clusterVersion string, specManager *sp
 		deployUser:     deployUser,
 		ignoreCheck:    ignoreCheck,
 		paths:          paths,
+		backup:         backup,
 	})
 	return b
 }
@@ -162,8 +179,45 @@ func (b *Builder) CopyComponent(pkgSrc, component, os, arch string,
 	return b
 }
 
+// BackupComponent appends a BackupComponent task to the current task collection
+func (b *Builder) BackupComponent(component, fromVer string, host, deployDir string) *Builder {
+	b.tasks = append(b.tasks, &BackupComponent{
+		component: component,
+		fromVer:   fromVer,
+		host:      host,
+		deployDir: deployDir,
+	})
+	return b
+}
+
+// PatchComponent appends a PatchComponent task to the current task collection
+func (b *Builder) PatchComponent(component, srcPath, host, deployDir string) *Builder {
+	b.tasks = append(b.tasks, &PatchComponent{
+		component: component,
+		srcPath:   srcPath,
+		host:      host,
+		deployDir: deployDir,
+	})
+	return b
+}
+
+// TLSCert generates certificate for instance and transfers it to the server
+func (b *Builder) TLSCert(host, comp, role string, port int, sans []string, ca *crypto.CertificateAuthority, caCert string, paths meta.DirPaths) *Builder {
+	b.tasks = append(b.tasks, &TLSCert{
+		host:   host,
+		sans:   sans,
+		comp:   comp,
+		role:   role,
+		port:   port,
+		ca:     ca,
+		caCert: caCert,
+		paths:  paths,
+	})
+	return b
+}
+
 // MonitoredConfig appends a CopyComponent task to the current task collection
-func (b *Builder) MonitoredConfig(clusterName, comp, host string, info *spec.MonitorHostInfo, globResCtl meta.ResourceControl, options *spec.TSMonitoredOptions, deployUser string, tlsEnabled bool, paths meta.DirPaths) *Builder {
+func (b *Builder) MonitoredConfig(clusterName, comp, host string, info *spec.MonitorHostInfo, globResCtl meta.ResourceControl, options *spec.TSMonitoredOptions, deployUser string, tlsEnabled bool, paths meta.DirPaths, backup bool) *Builder {
 	b.tasks = append(b.tasks, &MonitoredConfig{
 		clusterName: clusterName,
 		component:   comp,
@@ -174,6 +228,7 @@ func (b *Builder) MonitoredConfig(clusterName, comp, host string, info *spec.Mon
 		deployUser:  deployUser,
 		tlsEnabled:  tlsEnabled,
 		paths:       paths,
+		backup:      backup,
 	})
 	return b
 }
@@ -0,0 +1,201 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/openGemini/gemix/pkg/utils"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// localHost is the host name recorded for the tasks run on the control machine
+const localHost = "local"
+
+var (
+	checkpoint *CheckPoint // records the tasks completed in current operation
+	resumed    *CheckPoint // the checkpoint of the operation being resumed
+)
+
+// CheckPoint records the tasks completed on each host during an operation,
+// so that a failed operation can be resumed without repeating them.
+type CheckPoint struct {
+	Command string              `json:"command"`
+	Args    []string            `json:"args"`
+	Hosts   map[string][]string `json:"hosts"` // host -> completed tasks in order
+
+	mu   sync.Mutex
+	path string
+	done map[string]struct{}
+}
+
+// EnableCheckPoint makes the task engine record the completed tasks of the
+// operation `command args...` into the file at path.
+func EnableCheckPoint(path, command string, args []string) {
+	checkpoint = &CheckPoint{
+		Command: command,
+		Args:    args,
+		Hosts:   make(map[string][]string),
+		path:    path,
+		done:    make(map[string]struct{}),
+	}
+}
+
+// DiscardCheckPoint removes the checkpoint of current operation, it's called
+// when the operation is rolled back and cannot be resumed any more.
+func DiscardCheckPoint() error {
+	if checkpoint == nil {
+		return nil
+	}
+	checkpoint.mu.Lock()
+	defer checkpoint.mu.Unlock()
+
+	checkpoint.Hosts = make(map[string][]string)
+	checkpoint.done = make(map[string]struct{})
+	if err := os.Remove(checkpoint.path); err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// LoadCheckPoint loads the checkpoint file at path.
+func LoadCheckPoint(path string) (*CheckPoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	cp := &CheckPoint{done: make(map[string]struct{})}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, errors.WithMessagef(err, "parse checkpoint file %s", path)
+	}
+	for host, tasks := range cp.Hosts {
+		for _, t := range tasks {
+			cp.done[checkPointKey(host, t)] = struct{}{}
+		}
+	}
+	return cp, nil
+}
+
+// ResumeFrom makes the task engine skip the tasks completed in cp.
+func ResumeFrom(cp *CheckPoint) {
+	resumed = cp
+}
+
+// completed returns true if the task on host has been completed in the checkpoint.
+func (c *CheckPoint) completed(host, task string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.done[checkPointKey(host, task)]
+	return ok
+}
+
+// record adds the task on host to the checkpoint and saves it to the checkpoint file.
+func (c *CheckPoint) record(host, task string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := checkPointKey(host, task)
+	if _, ok := c.done[key]; ok {
+		return nil
+	}
+	c.done[key] = struct{}{}
+	c.Hosts[host] = append(c.Hosts[host], task)
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := utils.MkdirAll(filepath.Dir(c.path), 0750); err != nil {
+		return errors.WithStack(err)
+	}
+	return utils.WriteFile(c.path, data, 0640)
+}
+
+func checkPointKey(host, task string) string {
+	return fmt.Sprintf("%s/%s", host, task)
+}
+
+// isResumable returns true if the effect of the task persists on the hosts or
+// the control machine and does not depend on the state generated in the
+// operation, so it can be skipped when resuming the operation. The other tasks,
+// e.g. the ones preparing the context or the certificates signed by the CA
+// generated in every run, must always be executed.
+func isResumable(t Task) bool {
+	switch t.(type) {
+	case *Mkdir, *CopyComponent, *Downloader, *EnvInit, *UserAction:
+		return true
+	}
+	return false
+}
+
+// taskHost returns the host which the task performs on.
+func taskHost(t Task) string {
+	switch t := t.(type) {
+	case *BackupComponent:
+		return t.host
+	case *CopyComponent:
+		return t.host
+	case *EnvInit:
+		return t.host
+	case *InitConfig:
+		return t.instance.GetManageHost()
+	case *InstallPackage:
+		return t.host
+	case *Mkdir:
+		return t.host
+	case *MonitoredConfig:
+		return t.host
+	case *PatchComponent:
+		return t.host
+	case *TLSCert:
+		return t.host
+	case *UserAction:
+		return t.host
+	}
+	return localHost
+}
+
+// executeTask executes t unless it has been completed in the resumed
+// operation, the task is recorded to the checkpoint once it's completed.
+func executeTask(ctx context.Context, t Task) error {
+	if !isResumable(t) {
+		return t.Execute(ctx)
+	}
+
+	// some tasks fill their fields while executing, so the description
+	// must be taken before that to match the one of the resumed operation.
+	host, desc := taskHost(t), t.String()
+	if resumed.completed(host, desc) {
+		zap.L().Info("Skip the task completed before", zap.String("task", desc))
+	} else if err := t.Execute(ctx); err != nil {
+		return err
+	}
+	if err := checkpoint.record(host, desc); err != nil {
+		zap.L().Warn("Write checkpoint failed", zap.String("task", desc), zap.Error(err))
+	}
+	return nil
+}
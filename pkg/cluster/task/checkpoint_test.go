@@ -0,0 +1,157 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	logprinter "github.com/openGemini/gemix/pkg/logger/printer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeExecutor records the commands executed, the stdout of a command is the
+// one of the first rule whose pattern is contained in the command
+type fakeExecutor struct {
+	mu    sync.Mutex
+	cmds  []string
+	rules [][2]string // pattern, stdout
+}
+
+func (f *fakeExecutor) Execute(ctx context.Context, cmd string, sudo bool, timeout ...time.Duration) ([]byte, []byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cmds = append(f.cmds, cmd)
+	for _, r := range f.rules {
+		if strings.Contains(cmd, r[0]) {
+			return []byte(r[1]), nil, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+func (f *fakeExecutor) Transfer(ctx context.Context, src, dst string, download bool, limit int, compress bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cmds = append(f.cmds, "transfer "+src+" "+dst)
+	return nil
+}
+
+// newTestContext returns the context with the executor of host set
+func newTestContext(host string, e ctxt.Executor) context.Context {
+	ctx := ctxt.New(context.Background(), 1, logprinter.NewLogger(""))
+	ctxt.GetInner(ctx).SetExecutor(host, e)
+	return ctx
+}
+
+// resetCheckPoint disables the checkpoint and the resumed one after the test
+func resetCheckPoint(t *testing.T) {
+	t.Cleanup(func() {
+		checkpoint, resumed = nil, nil
+	})
+}
+
+func TestIsResumable(t *testing.T) {
+	for _, tc := range []struct {
+		task      Task
+		resumable bool
+	}{
+		{&Mkdir{}, true},
+		{&CopyComponent{}, true},
+		{&Downloader{}, true},
+		{&EnvInit{}, true},
+		{&UserAction{}, true},
+		{&TLSCert{}, false},
+		{&InitConfig{}, false},
+		{&MonitoredConfig{}, false},
+		{&InstallPackage{}, false},
+		{&BackupComponent{}, false},
+		{&PatchComponent{}, false},
+		{&RootSSH{}, false},
+		{&SSHKeySet{}, false},
+		{&Func{}, false},
+		{&StepDisplay{inner: &Mkdir{}}, false},
+	} {
+		assert.Equal(t, tc.resumable, isResumable(tc.task), "%T", tc.task)
+	}
+}
+
+func TestCheckPointRecord(t *testing.T) {
+	resetCheckPoint(t)
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	EnableCheckPoint(path, "gemix cluster install", []string{"c1"})
+
+	e := &fakeExecutor{}
+	ctx := newTestContext("h1", e)
+	mkdir := &Mkdir{user: "gemini", host: "h1", dirs: []string{"/data"}}
+	require.NoError(t, executeTask(ctx, mkdir))
+	require.NoError(t, executeTask(ctx, NewFunc("func", func(ctx context.Context) error { return nil })))
+	assert.NotEmpty(t, e.cmds)
+
+	cp, err := LoadCheckPoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, "gemix cluster install", cp.Command)
+	assert.Equal(t, []string{"c1"}, cp.Args)
+	// only the resumable tasks are recorded
+	assert.Equal(t, map[string][]string{"h1": {mkdir.String()}}, cp.Hosts)
+	assert.True(t, cp.completed("h1", mkdir.String()))
+	assert.False(t, cp.completed("h2", mkdir.String()))
+}
+
+func TestCheckPointResume(t *testing.T) {
+	resetCheckPoint(t)
+	dir := t.TempDir()
+	done := &Mkdir{user: "gemini", host: "h1", dirs: []string{"/data"}}
+	EnableCheckPoint(filepath.Join(dir, "failed"), "gemix cluster install", []string{"c1"})
+	require.NoError(t, checkpoint.record("h1", done.String()))
+	require.NoError(t, checkpoint.record(localHost, "func"))
+
+	cp, err := LoadCheckPoint(filepath.Join(dir, "failed"))
+	require.NoError(t, err)
+	ResumeFrom(cp)
+	path := filepath.Join(dir, "resumed")
+	EnableCheckPoint(path, "gemix cluster install", []string{"c1"})
+
+	e := &fakeExecutor{}
+	ctx := newTestContext("h1", e)
+
+	// the completed task is skipped
+	require.NoError(t, executeTask(ctx, &Mkdir{user: "gemini", host: "h1", dirs: []string{"/data"}}))
+	assert.Empty(t, e.cmds)
+
+	// the task with different fields is executed
+	other := &Mkdir{user: "gemini", host: "h1", dirs: []string{"/log"}}
+	require.NoError(t, executeTask(ctx, other))
+	assert.NotEmpty(t, e.cmds)
+
+	// the tasks which are not resumable are always executed
+	executed := false
+	require.NoError(t, executeTask(ctx, NewFunc("func", func(ctx context.Context) error {
+		executed = true
+		return nil
+	})))
+	assert.True(t, executed)
+
+	// the skipped tasks are recorded again so that the operation can be resumed once more
+	cp, err = LoadCheckPoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"h1": {done.String(), other.String()}}, cp.Hosts)
+}
@@ -33,6 +33,7 @@ type CopyComponent struct {
 	host       string
 	srcPath    string
 	dstDir     string
+	install    *InstallPackage
 }
 
 // Execute implements the Task interface
@@ -53,19 +54,22 @@ func (c *CopyComponent) Execute(ctx context.Context) error {
 		srcPath = spec.PackagePath(c.srcPkgName, c.version, c.os, c.arch)
 	}
 
-	install := &InstallPackage{
+	c.install = &InstallPackage{
 		component: c.component,
 		srcPath:   srcPath,
 		host:      c.host,
 		dstDir:    c.dstDir,
 	}
 
-	return install.Execute(ctx)
+	return c.install.Execute(ctx)
 }
 
 // Rollback implements the Task interface
 func (c *CopyComponent) Rollback(ctx context.Context) error {
-	return ErrUnsupportedRollback
+	if c.install == nil {
+		return nil
+	}
+	return c.install.Rollback(ctx)
 }
 
 // String implements the fmt.Stringer interface
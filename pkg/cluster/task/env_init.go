@@ -22,6 +22,7 @@ import (
 	"github.com/joomcode/errorx"
 	"github.com/openGemini/gemix/pkg/cluster/ctxt"
 	"github.com/openGemini/gemix/pkg/cluster/executor"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -38,8 +39,16 @@ type EnvInit struct {
 	host       string
 	deployUser string
 	userGroup  string
+
+	// the changes to be undone on rollback
+	sshDirCreated     bool
+	authorizedKey     string
+	authorizedKeyFile string
 }
 
+// envInitChanged is printed when the environment is changed by a command
+const envInitChanged = "changed"
+
 // Execute implements the Task interface
 func (e *EnvInit) Execute(ctx context.Context) error {
 	return e.exec(ctx)
@@ -61,29 +70,57 @@ func (e *EnvInit) exec(ctx context.Context) error {
 	}
 
 	// Authorize
-	cmd := `su - ` + e.deployUser + ` -c 'mkdir -p ~/.ssh && chmod 700 ~/.ssh'`
-	_, _, err = exec.Execute(ctx, cmd, true)
+	cmd := fmt.Sprintf(`su - %[1]s -c 'test -d ~/.ssh || (mkdir -p ~/.ssh && echo %[2]s) && chmod 700 ~/.ssh'`,
+		e.deployUser, envInitChanged)
+	stdout, _, err := exec.Execute(ctx, cmd, true)
 	if err != nil {
 		return wrapError(errEnvInitSubCommandFailed.
 			Wrap(err, "Failed to create '~/.ssh' directory for user '%s'", e.deployUser))
 	}
+	e.sshDirCreated = strings.TrimSpace(string(stdout)) == envInitChanged
 
 	pk := strings.TrimSpace(string(pubKey))
 	sshAuthorizedKeys := executor.FindSSHAuthorizedKeysFile(ctx, exec)
-	cmd = fmt.Sprintf(`su - %[1]s -c 'grep $(echo %[2]s) %[3]s || echo %[2]s >> %[3]s && chmod 600 %[3]s'`,
-		e.deployUser, pk, sshAuthorizedKeys)
-	_, _, err = exec.Execute(ctx, cmd, true)
+	cmd = fmt.Sprintf(`su - %[1]s -c 'grep -qxF "%[2]s" %[3]s 2>/dev/null || (echo %[2]s >> %[3]s && echo %[4]s) && chmod 600 %[3]s'`,
+		e.deployUser, pk, sshAuthorizedKeys, envInitChanged)
+	stdout, _, err = exec.Execute(ctx, cmd, true)
 	if err != nil {
 		return wrapError(errEnvInitSubCommandFailed.
 			Wrap(err, "Failed to write public keys to '%s' for user '%s'", sshAuthorizedKeys, e.deployUser))
 	}
+	if strings.TrimSpace(string(stdout)) == envInitChanged {
+		e.authorizedKey, e.authorizedKeyFile = pk, sshAuthorizedKeys
+	}
 
 	return nil
 }
 
 // Rollback implements the Task interface
 func (e *EnvInit) Rollback(ctx context.Context) error {
-	return ErrUnsupportedRollback
+	if !e.sshDirCreated && e.authorizedKey == "" {
+		return nil
+	}
+	exec, found := ctxt.GetInner(ctx).GetExecutor(e.host)
+	if !found {
+		panic(ErrNoExecutor)
+	}
+
+	if e.authorizedKey != "" {
+		cmd := fmt.Sprintf(`su - %[1]s -c 'grep -vxF "%[2]s" %[3]s > %[3]s.gemix; cat %[3]s.gemix > %[3]s && rm -f %[3]s.gemix'`,
+			e.deployUser, e.authorizedKey, e.authorizedKeyFile)
+		if _, _, err := exec.Execute(ctx, cmd, true); err != nil {
+			return errors.WithMessagef(err, "remove the public key from '%s' for user '%s'", e.authorizedKeyFile, e.deployUser)
+		}
+		e.authorizedKey = ""
+	}
+	if e.sshDirCreated {
+		cmd := fmt.Sprintf(`su - %s -c 'rm -rf ~/.ssh'`, e.deployUser)
+		if _, _, err := exec.Execute(ctx, cmd, true); err != nil {
+			return errors.WithMessagef(err, "remove '~/.ssh' directory for user '%s'", e.deployUser)
+		}
+		e.sshDirCreated = false
+	}
+	return nil
 }
 
 // String implements the fmt.Stringer interface
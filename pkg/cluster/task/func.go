@@ -17,8 +17,10 @@ import "context"
 
 // Func wrap a closure.
 type Func struct {
-	name string
-	fn   func(ctx context.Context) error
+	name     string
+	fn       func(ctx context.Context) error
+	rollback func(ctx context.Context) error
+	executed bool
 }
 
 // NewFunc create a Func task
@@ -31,12 +33,20 @@ func NewFunc(name string, fn func(ctx context.Context) error) *Func {
 
 // Execute implements the Task interface
 func (m *Func) Execute(ctx context.Context) error {
+	m.executed = true
 	return m.fn(ctx)
 }
 
 // Rollback implements the Task interface
-func (m *Func) Rollback(_ context.Context) error {
-	return ErrUnsupportedRollback
+func (m *Func) Rollback(ctx context.Context) error {
+	if m.rollback == nil {
+		return ErrUnsupportedRollback
+	}
+	if !m.executed {
+		return nil
+	}
+	m.executed = false
+	return m.rollback(ctx)
 }
 
 // String implements the fmt.Stringer interface
@@ -17,6 +17,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/openGemini/gemix/pkg/cluster/ctxt"
 	"github.com/openGemini/gemix/pkg/cluster/spec"
@@ -33,10 +34,12 @@ type InitConfig struct {
 	deployUser     string
 	ignoreCheck    bool
 	paths          meta.DirPaths
+	backup         bool // back up the overwritten files to restore them on rollback
+	files          remoteFiles
 }
 
 // Execute implements the Task interface
-func (c *InitConfig) Execute(ctx context.Context) error {
+func (c *InitConfig) Execute(ctx context.Context) (err error) {
 	// Copy to remote server
 	exec, found := ctxt.GetInner(ctx).GetExecutor(c.instance.GetManageHost())
 	if !found {
@@ -47,7 +50,26 @@ func (c *InitConfig) Execute(ctx context.Context) error {
 		return errors.WithMessagef(err, "create cache directory failed: %s", c.paths.Cache)
 	}
 
-	err := c.instance.InitConfig(ctx, exec, c.clusterName, c.clusterVersion, c.deployUser, c.paths)
+	if err := c.files.snapshot(ctx, exec,
+		c.paths.Deploy,
+		filepath.Join(c.paths.Deploy, "conf"),
+		filepath.Join(c.paths.Deploy, "scripts"),
+		filepath.Join(systemdUnitDir, c.instance.ServiceName()),
+	); err != nil {
+		return err
+	}
+	if c.backup {
+		if err := c.files.backup(ctx, exec); err != nil {
+			return err
+		}
+	}
+	defer func() {
+		if e := c.files.diff(ctx, exec); err == nil {
+			err = e
+		}
+	}()
+
+	err = c.instance.InitConfig(ctx, exec, c.clusterName, c.clusterVersion, c.deployUser, c.paths)
 	if err != nil {
 		return errors.WithMessagef(err, "init config failed: %s:%d", c.instance.GetManageHost(), c.instance.GetPort())
 	}
@@ -56,7 +78,14 @@ func (c *InitConfig) Execute(ctx context.Context) error {
 
 // Rollback implements the Task interface
 func (c *InitConfig) Rollback(ctx context.Context) error {
-	return ErrUnsupportedRollback
+	if !c.files.changed() {
+		return nil
+	}
+	exec, found := ctxt.GetInner(ctx).GetExecutor(c.instance.GetManageHost())
+	if !found {
+		return ErrNoExecutor
+	}
+	return c.files.remove(ctx, exec)
 }
 
 // String implements the fmt.Stringer interface
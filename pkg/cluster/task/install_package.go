@@ -31,10 +31,11 @@ type InstallPackage struct {
 	srcPath   string // srcPath like "/home/gemix/xxx/pkg.tar.gz"
 	host      string
 	dstDir    string
+	files     remoteFiles
 }
 
 // Execute implements the Task interface
-func (c *InstallPackage) Execute(ctx context.Context) error {
+func (c *InstallPackage) Execute(ctx context.Context) (err error) {
 	// Install package to remote server
 	exec, found := ctxt.GetInner(ctx).GetExecutor(c.host)
 	if !found {
@@ -42,9 +43,18 @@ func (c *InstallPackage) Execute(ctx context.Context) error {
 	}
 
 	dstDir := filepath.Join(c.dstDir, "bin")
+	if err := c.files.snapshot(ctx, exec, dstDir); err != nil {
+		return err
+	}
+	defer func() {
+		// the files left by a failed installation are removed on rollback too
+		if e := c.files.diff(ctx, exec); err == nil {
+			err = e
+		}
+	}()
 	dstPath := filepath.Join(dstDir, path.Base(c.srcPath))
 
-	err := exec.Transfer(ctx, c.srcPath, dstPath, false, 0, false)
+	err = exec.Transfer(ctx, c.srcPath, dstPath, false, 0, false)
 	if err != nil {
 		return errors.WithMessagef(err, "failed to scp %s to %s:%s", c.srcPath, c.host, dstPath)
 	}
@@ -65,7 +75,14 @@ func (c *InstallPackage) Execute(ctx context.Context) error {
 
 // Rollback implements the Task interface
 func (c *InstallPackage) Rollback(ctx context.Context) error {
-	return ErrUnsupportedRollback
+	if !c.files.changed() {
+		return nil
+	}
+	exec, found := ctxt.GetInner(ctx).GetExecutor(c.host)
+	if !found {
+		return ErrNoExecutor
+	}
+	return c.files.remove(ctx, exec)
 }
 
 // String implements the fmt.Stringer interface
@@ -22,11 +22,15 @@ import (
 	"github.com/pkg/errors"
 )
 
+// mkdirCreated is printed when the directory does not exist and is created
+const mkdirCreated = "created"
+
 // Mkdir is used to create directory on the target host
 type Mkdir struct {
-	user string
-	host string
-	dirs []string
+	user    string
+	host    string
+	dirs    []string
+	created []string // the directories created by the task
 }
 
 // Execute implements the Task interface
@@ -57,15 +61,20 @@ func (m *Mkdir) Execute(ctx context.Context) error {
 			if xs[i] == "" {
 				continue
 			}
+			path := strings.Join(xs[:i+1], "/")
 			cmd := fmt.Sprintf(
-				`test -d %[1]s || (mkdir -p %[1]s && chown %[2]s:$(id -g -n %[2]s) %[1]s)`,
-				strings.Join(xs[:i+1], "/"),
+				`test -d %[1]s || (mkdir -p %[1]s && chown %[2]s:$(id -g -n %[2]s) %[1]s && echo %[3]s)`,
+				path,
 				m.user,
+				mkdirCreated,
 			)
-			_, _, err := exec.Execute(ctx, cmd, true) // use root to create the dir
+			stdout, _, err := exec.Execute(ctx, cmd, true) // use root to create the dir
 			if err != nil {
 				return errors.WithStack(err)
 			}
+			if strings.TrimSpace(string(stdout)) == mkdirCreated {
+				m.created = append(m.created, path)
+			}
 		}
 	}
 
@@ -74,7 +83,22 @@ func (m *Mkdir) Execute(ctx context.Context) error {
 
 // Rollback implements the Task interface
 func (m *Mkdir) Rollback(ctx context.Context) error {
-	return ErrUnsupportedRollback
+	if len(m.created) == 0 {
+		return nil
+	}
+	exec, found := ctxt.GetInner(ctx).GetExecutor(m.host)
+	if !found {
+		panic(ErrNoExecutor)
+	}
+	// remove in reverse order, the children before their parents
+	for i := len(m.created) - 1; i >= 0; i-- {
+		cmd := fmt.Sprintf("rm -rf %s", m.created[i])
+		if _, _, err := exec.Execute(ctx, cmd, true); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	m.created = nil
+	return nil
 }
 
 // String implements the fmt.Stringer interface
@@ -42,16 +42,36 @@ type MonitoredConfig struct {
 	deployUser  string
 	tlsEnabled  bool
 	paths       meta.DirPaths
+	backup      bool // back up the overwritten files to restore them on rollback
+	files       remoteFiles
 }
 
 // Execute implements the Task interface
-func (m *MonitoredConfig) Execute(ctx context.Context) error {
+func (m *MonitoredConfig) Execute(ctx context.Context) (err error) {
 	// Copy to remote server
 	exec, found := ctxt.GetInner(ctx).GetExecutor(m.host)
 	if !found {
 		return ErrNoExecutor
 	}
 
+	if err := m.files.snapshot(ctx, exec,
+		filepath.Join(m.paths.Deploy, "conf"),
+		filepath.Join(m.paths.Deploy, "scripts"),
+		filepath.Join(systemdUnitDir, fmt.Sprintf("%s.service", m.component)),
+	); err != nil {
+		return err
+	}
+	if m.backup {
+		if err := m.files.backup(ctx, exec); err != nil {
+			return err
+		}
+	}
+	defer func() {
+		if e := m.files.diff(ctx, exec); err == nil {
+			err = e
+		}
+	}()
+
 	if err := utils.MkdirAll(m.paths.Cache, 0755); err != nil {
 		return err
 	}
@@ -153,7 +173,14 @@ func (m *MonitoredConfig) syncTsMonitorConfig(ctx context.Context, exec ctxt.Exe
 
 // Rollback implements the Task interface
 func (m *MonitoredConfig) Rollback(ctx context.Context) error {
-	return ErrUnsupportedRollback
+	if !m.files.changed() {
+		return nil
+	}
+	exec, found := ctxt.GetInner(ctx).GetExecutor(m.host)
+	if !found {
+		return ErrNoExecutor
+	}
+	return m.files.remove(ctx, exec)
 }
 
 // String implements the fmt.Stringer interface
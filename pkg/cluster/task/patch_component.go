@@ -0,0 +1,69 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	"github.com/pkg/errors"
+)
+
+// PatchComponent is used to replace the binary of a component with the one in the patch package
+type PatchComponent struct {
+	component string // component name like "ts-meta/ts-sql/ts-store"
+	srcPath   string // srcPath like "/home/gemix/ts-store.tar.gz"
+	host      string
+	deployDir string
+}
+
+// Execute implements the Task interface
+func (c *PatchComponent) Execute(ctx context.Context) error {
+	exec, found := ctxt.GetInner(ctx).GetExecutor(c.host)
+	if !found {
+		return ErrNoExecutor
+	}
+
+	binDir := filepath.Join(c.deployDir, "bin")
+	tmpDir := filepath.Join(binDir, ".patch")
+	dstPath := filepath.Join(binDir, filepath.Base(c.srcPath))
+
+	err := exec.Transfer(ctx, c.srcPath, dstPath, false, 0, false)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to scp %s to %s:%s", c.srcPath, c.host, dstPath)
+	}
+
+	// the binary may be in any directory of the package, e.g. usr/bin/ts-store
+	cmd := fmt.Sprintf(`rm -rf %[1]s && mkdir -p %[1]s && tar --no-same-owner -zxf %[2]s -C %[1]s && `+
+		`find %[1]s -type f -name %[3]s -exec mv -f {} %[4]s/%[3]s \; && chmod +x %[4]s/%[3]s && rm -rf %[1]s %[2]s`,
+		tmpDir, dstPath, c.component, binDir)
+	_, stderr, err := exec.Execute(ctx, cmd, false)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to patch %s on %s, stderr: %s", c.component, c.host, string(stderr))
+	}
+	return nil
+}
+
+// Rollback implements the Task interface
+func (c *PatchComponent) Rollback(ctx context.Context) error {
+	return ErrUnsupportedRollback
+}
+
+// String implements the fmt.Stringer interface
+func (c *PatchComponent) String() string {
+	return fmt.Sprintf("PatchComponent: component=%s, srcPath=%s, remote=%s:%s", c.component, c.srcPath, c.host, c.deployDir)
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	"github.com/pkg/errors"
+)
+
+// systemdUnitDir is the directory of the systemd units installed by gemix
+const systemdUnitDir = "/etc/systemd/system"
+
+// remoteFiles tracks the files and directories created on a host by a task,
+// so that they can be removed when the task is rolled back. The files which
+// existed before are restored from the backup if backup is called.
+type remoteFiles struct {
+	paths     []string // the watched files, and directories with their direct entries
+	before    map[string]struct{}
+	created   []string
+	backupDir string   // the dir holding the copies of the existing files
+	saved     []string // the files copied to the backup dir
+}
+
+// snapshot lists the watched paths before the task changes them.
+func (r *remoteFiles) snapshot(ctx context.Context, e ctxt.Executor, paths ...string) error {
+	r.paths = paths
+	files, err := r.list(ctx, e)
+	if err != nil {
+		return err
+	}
+	r.before = files
+	return nil
+}
+
+// backup copies the existing watched files to a temporary dir on the host, so
+// that the files overwritten by the task are restored when it is rolled back.
+func (r *remoteFiles) backup(ctx context.Context, e ctxt.Executor) error {
+	cmd := fmt.Sprintf("find %s -maxdepth 1 -type f 2>/dev/null; true", strings.Join(r.paths, " "))
+	stdout, stderr, err := e.Execute(ctx, cmd, true)
+	if err != nil {
+		return errors.WithMessagef(err, "stderr: %s", string(stderr))
+	}
+	var files []string
+	for _, line := range strings.Split(string(stdout), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	// the files are copied to the same paths under the backup dir
+	dir := filepath.Join("/tmp", "gemix-rollback-"+uuid.New().String())
+	cmds := make([]string, 0, len(files))
+	for _, f := range files {
+		cmds = append(cmds, fmt.Sprintf("mkdir -p %s && cp -a %s %s", filepath.Join(dir, filepath.Dir(f)), f, filepath.Join(dir, f)))
+	}
+	if _, stderr, err = e.Execute(ctx, strings.Join(cmds, " && "), true); err != nil {
+		return errors.WithMessagef(err, "stderr: %s", string(stderr))
+	}
+	r.backupDir = dir
+	r.saved = files
+	return nil
+}
+
+// diff records the watched paths which did not exist in the snapshot.
+func (r *remoteFiles) diff(ctx context.Context, e ctxt.Executor) error {
+	if r.before == nil {
+		return nil
+	}
+	files, err := r.list(ctx, e)
+	if err != nil {
+		return err
+	}
+	for f := range files {
+		if _, ok := r.before[f]; !ok {
+			r.created = append(r.created, f)
+		}
+	}
+	return nil
+}
+
+func (r *remoteFiles) list(ctx context.Context, e ctxt.Executor) (map[string]struct{}, error) {
+	cmd := fmt.Sprintf("find %s -maxdepth 1 2>/dev/null; true", strings.Join(r.paths, " "))
+	stdout, stderr, err := e.Execute(ctx, cmd, true)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "stderr: %s", string(stderr))
+	}
+	files := make(map[string]struct{})
+	for _, line := range strings.Split(string(stdout), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files[line] = struct{}{}
+		}
+	}
+	return files, nil
+}
+
+// changed returns true if there are paths to be removed or restored.
+func (r *remoteFiles) changed() bool {
+	return len(r.created) > 0 || len(r.saved) > 0
+}
+
+// remove removes the created paths and restores the saved files, systemd
+// reloads the units if any of them are removed or restored.
+func (r *remoteFiles) remove(ctx context.Context, e ctxt.Executor) error {
+	if !r.changed() {
+		return nil
+	}
+	var cmds []string
+	if len(r.created) > 0 {
+		cmds = append(cmds, "rm -rf "+strings.Join(r.created, " "))
+	}
+	for _, f := range r.saved {
+		cmds = append(cmds, fmt.Sprintf("cp -a %s %s", filepath.Join(r.backupDir, f), f))
+	}
+	if r.backupDir != "" {
+		cmds = append(cmds, "rm -rf "+r.backupDir)
+	}
+	for _, f := range append(r.created, r.saved...) {
+		if strings.HasPrefix(f, systemdUnitDir+"/") {
+			cmds = append(cmds, "(systemctl daemon-reload || true)")
+			break
+		}
+	}
+	if _, stderr, err := e.Execute(ctx, strings.Join(cmds, " && "), true); err != nil {
+		return errors.WithMessagef(err, "stderr: %s", string(stderr))
+	}
+	r.created = nil
+	r.saved = nil
+	r.backupDir = ""
+	return nil
+}
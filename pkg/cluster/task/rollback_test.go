@@ -0,0 +1,211 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	logprinter "github.com/openGemini/gemix/pkg/logger/printer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shellExecutor runs the commands with the local shell, it's used to check the
+// changes on the files in a temporary dir
+type shellExecutor struct{}
+
+func (shellExecutor) Execute(ctx context.Context, cmd string, sudo bool, timeout ...time.Duration) ([]byte, []byte, error) {
+	c := exec.CommandContext(ctx, "sh", "-c", cmd)
+	var stderr strings.Builder
+	c.Stderr = &stderr
+	stdout, err := c.Output()
+	return stdout, []byte(stderr.String()), err
+}
+
+func (shellExecutor) Transfer(ctx context.Context, src, dst string, download bool, limit int, compress bool) error {
+	return nil
+}
+
+func writeFile(t *testing.T, path, data string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+}
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRemoteFilesRemove(t *testing.T) {
+	dir := t.TempDir()
+	deploy := filepath.Join(dir, "deploy")
+	writeFile(t, filepath.Join(deploy, "conf", "ts-sql.toml"), "old")
+	writeFile(t, filepath.Join(deploy, "conf", "kept", "file"), "kept")
+	writeFile(t, filepath.Join(dir, "unwatched"), "unwatched")
+
+	ctx := context.Background()
+	e := shellExecutor{}
+	var files remoteFiles
+	require.NoError(t, files.snapshot(ctx, e, deploy, filepath.Join(deploy, "conf"), filepath.Join(deploy, "scripts")))
+	require.NoError(t, files.backup(ctx, e))
+	assert.Equal(t, []string{filepath.Join(deploy, "conf", "ts-sql.toml")}, files.saved)
+	backupDir := files.backupDir
+	assert.DirExists(t, backupDir)
+
+	// the task overwrites the config and creates the scripts
+	writeFile(t, filepath.Join(deploy, "conf", "ts-sql.toml"), "new")
+	writeFile(t, filepath.Join(deploy, "conf", "kept", "new"), "new")
+	writeFile(t, filepath.Join(deploy, "scripts", "run_ts-sql.sh"), "run")
+	writeFile(t, filepath.Join(dir, "unwatched.new"), "unwatched")
+	require.NoError(t, files.diff(ctx, e))
+	assert.ElementsMatch(t, []string{
+		filepath.Join(deploy, "scripts"),
+		filepath.Join(deploy, "scripts", "run_ts-sql.sh"),
+	}, files.created)
+
+	require.NoError(t, files.remove(ctx, e))
+	assert.Equal(t, "old", readFile(t, filepath.Join(deploy, "conf", "ts-sql.toml")))
+	assert.NoDirExists(t, filepath.Join(deploy, "scripts"))
+	assert.NoDirExists(t, backupDir)
+	// the paths which are not watched or not direct entries are kept
+	assert.FileExists(t, filepath.Join(deploy, "conf", "kept", "file"))
+	assert.FileExists(t, filepath.Join(deploy, "conf", "kept", "new"))
+	assert.FileExists(t, filepath.Join(dir, "unwatched"))
+	assert.FileExists(t, filepath.Join(dir, "unwatched.new"))
+
+	// nothing is done if it's removed again
+	writeFile(t, filepath.Join(deploy, "conf", "ts-sql.toml"), "newer")
+	require.NoError(t, files.remove(ctx, e))
+	assert.Equal(t, "newer", readFile(t, filepath.Join(deploy, "conf", "ts-sql.toml")))
+}
+
+func TestRemoteFilesNotSnapshot(t *testing.T) {
+	e := &fakeExecutor{}
+	var files remoteFiles
+	require.NoError(t, files.diff(context.Background(), e))
+	require.NoError(t, files.remove(context.Background(), e))
+	assert.Empty(t, e.cmds)
+}
+
+func TestMkdirRollback(t *testing.T) {
+	e := &fakeExecutor{rules: [][2]string{
+		{"test -d /data/gemini ||", mkdirCreated},
+		{"test -d /data/gemini/deploy ||", mkdirCreated},
+	}}
+	ctx := newTestContext("h1", e)
+	m := &Mkdir{user: "gemini", host: "h1", dirs: []string{"/data/gemini/deploy"}}
+	require.NoError(t, m.Execute(ctx))
+
+	e.cmds = nil
+	require.NoError(t, m.Rollback(ctx))
+	// the existing parent is kept, the children are removed before their parents
+	assert.Equal(t, []string{"rm -rf /data/gemini/deploy", "rm -rf /data/gemini"}, e.cmds)
+
+	e.cmds = nil
+	require.NoError(t, m.Rollback(ctx))
+	assert.Empty(t, e.cmds)
+}
+
+func TestEnvInitRollback(t *testing.T) {
+	pubKey := filepath.Join(t.TempDir(), "id_rsa.pub")
+	writeFile(t, pubKey, "ssh-rsa AAAA gemix\n")
+	newContext := func(e ctxt.Executor) context.Context {
+		ctx := newTestContext("h1", e)
+		ctxt.GetInner(ctx).PublicKeyPath = pubKey
+		return ctx
+	}
+
+	// the key is appended to the existing authorized keys
+	e := &fakeExecutor{rules: [][2]string{{"grep -qxF", envInitChanged}}}
+	ctx := newContext(e)
+	env := &EnvInit{host: "h1", deployUser: "gemini"}
+	require.NoError(t, env.Execute(ctx))
+	e.cmds = nil
+	require.NoError(t, env.Rollback(ctx))
+	require.Len(t, e.cmds, 1)
+	assert.Contains(t, e.cmds[0], `grep -vxF "ssh-rsa AAAA gemix" ~/.ssh/authorized_keys`)
+	assert.NotContains(t, e.cmds[0], "rm -rf ~/.ssh")
+
+	// nothing is changed if the key is authorized before
+	e = &fakeExecutor{}
+	ctx = newContext(e)
+	env = &EnvInit{host: "h1", deployUser: "gemini"}
+	require.NoError(t, env.Execute(ctx))
+	e.cmds = nil
+	require.NoError(t, env.Rollback(ctx))
+	assert.Empty(t, e.cmds)
+
+	// the ssh dir created is removed
+	e = &fakeExecutor{rules: [][2]string{{"mkdir -p ~/.ssh", envInitChanged}, {"grep -qxF", envInitChanged}}}
+	ctx = newContext(e)
+	env = &EnvInit{host: "h1", deployUser: "gemini"}
+	require.NoError(t, env.Execute(ctx))
+	e.cmds = nil
+	require.NoError(t, env.Rollback(ctx))
+	require.Len(t, e.cmds, 2)
+	assert.Equal(t, `su - gemini -c 'rm -rf ~/.ssh'`, e.cmds[1])
+}
+
+func TestUserActionRollback(t *testing.T) {
+	// the existing user is kept
+	e := &fakeExecutor{}
+	ctx := newTestContext("h1", e)
+	u := &UserAction{host: "h1", userAction: UserActionAdd, name: "gemini", sudoer: true}
+	require.NoError(t, u.Execute(ctx))
+	e.cmds = nil
+	require.NoError(t, u.Rollback(ctx))
+	assert.Empty(t, e.cmds)
+
+	// the user, group and sudoers file created are removed
+	e = &fakeExecutor{rules: [][2]string{{"useradd", strings.Join([]string{groupCreatedMarker, userCreatedMarker, sudoerCreatedMarker}, "\n")}}}
+	ctx = newTestContext("h1", e)
+	u = &UserAction{host: "h1", userAction: UserActionAdd, name: "gemini", sudoer: true}
+	require.NoError(t, u.Execute(ctx))
+	e.cmds = nil
+	require.NoError(t, u.Rollback(ctx))
+	require.Len(t, e.cmds, 1)
+	assert.Equal(t, "rm -f /etc/sudoers.d/gemini && (/usr/sbin/userdel -r gemini || [ $? -eq 6 ]) && "+
+		"(! getent group gemini > /dev/null || /usr/sbin/groupdel gemini)", e.cmds[0])
+
+	e.cmds = nil
+	require.NoError(t, u.Rollback(ctx))
+	assert.Empty(t, e.cmds)
+}
+
+func TestRollbackNotExecuted(t *testing.T) {
+	// no executor is set, the tasks must not touch the host
+	ctx := ctxt.New(context.Background(), 1, logprinter.NewLogger(""))
+	for _, tk := range []Task{
+		&Mkdir{host: "h1", dirs: []string{"/data"}},
+		&EnvInit{host: "h1", deployUser: "gemini"},
+		&UserAction{host: "h1", userAction: UserActionAdd, name: "gemini"},
+		&InitConfig{},
+		&MonitoredConfig{host: "h1"},
+		&InstallPackage{host: "h1"},
+		&CopyComponent{host: "h1"},
+	} {
+		assert.NotPanics(t, func() {
+			assert.NoError(t, tk.Rollback(ctx), "%T", tk)
+		}, "%T", tk)
+	}
+}
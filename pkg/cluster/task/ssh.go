@@ -29,14 +29,15 @@ var (
 
 // RootSSH is used to establish a SSH connection to the target host with specific key
 type RootSSH struct {
-	host       string // hostname of the SSH server
-	port       int    // port of the SSH server
-	user       string // username to login to the SSH server
-	password   string // password of the user
-	keyFile    string // path to the private key file
-	passphrase string // passphrase of the private key file
-	timeout    uint64 // timeout in seconds when connecting via SSH
-	exeTimeout uint64 // timeout in seconds waiting command to finish
+	host       string           // hostname of the SSH server
+	port       int              // port of the SSH server
+	user       string           // username to login to the SSH server
+	password   string           // password of the user
+	keyFile    string           // path to the private key file
+	passphrase string           // passphrase of the private key file
+	timeout    uint64           // timeout in seconds when connecting via SSH
+	exeTimeout uint64           // timeout in seconds waiting command to finish
+	sshType    executor.SSHType // the type of the connection, e.g. builtin SSH or local
 	//proxyHost       string           // hostname of the proxy SSH server
 	//proxyPort       int              // port of the proxy SSH server
 	//proxyUser       string           // username to login to the proxy SSH server
@@ -58,7 +59,7 @@ func (s *RootSSH) Execute(ctx context.Context) error {
 		Timeout:    time.Second * time.Duration(s.timeout),
 		ExeTimeout: time.Second * time.Duration(s.exeTimeout),
 	}
-	e, err := executor.New(s.user != "root", sc)
+	e, err := executor.New(s.sshType, s.user != "root", sc)
 	if err != nil {
 		return err
 	}
@@ -86,14 +87,15 @@ type UserSSH struct {
 	port            int
 	deployUser      string
 	timeout         uint64
-	exeTimeout      uint64 // timeout in seconds waiting command to finish
-	proxyHost       string // hostname of the proxy SSH server
-	proxyPort       int    // port of the proxy SSH server
-	proxyUser       string // username to login to the proxy SSH server
-	proxyPassword   string // password of the proxy user
-	proxyKeyFile    string // path to the private key file
-	proxyPassphrase string // passphrase of the private key file
-	proxyTimeout    uint64 // timeout in seconds when connecting via SSH
+	exeTimeout      uint64           // timeout in seconds waiting command to finish
+	proxyHost       string           // hostname of the proxy SSH server
+	proxyPort       int              // port of the proxy SSH server
+	proxyUser       string           // username to login to the proxy SSH server
+	proxyPassword   string           // password of the proxy user
+	proxyKeyFile    string           // path to the private key file
+	proxyPassphrase string           // passphrase of the private key file
+	proxyTimeout    uint64           // timeout in seconds when connecting via SSH
+	sshType         executor.SSHType // the type of the connection, e.g. builtin SSH or local
 }
 
 // Execute implements the Task interface
@@ -119,7 +121,7 @@ func (s *UserSSH) Execute(ctx context.Context) error {
 			Timeout:    time.Second * time.Duration(s.proxyTimeout),
 		}
 	}
-	e, err := executor.New(false, sc)
+	e, err := executor.New(s.sshType, false, sc)
 	if err != nil {
 		return err
 	}
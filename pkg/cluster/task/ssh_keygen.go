@@ -31,7 +31,8 @@ import (
 
 // SSHKeyGen is used to generate SSH key
 type SSHKeyGen struct {
-	keypath string
+	keypath   string
+	generated bool // the key pair is generated by the task rather than existing before
 }
 
 // Execute implements the Task interface
@@ -70,6 +71,7 @@ func (s *SSHKeyGen) Execute(ctx context.Context) error {
 		return errors.WithStack(err)
 	}
 
+	s.generated = true
 	ctxt.GetInner(ctx).PublicKeyPath = savePublicFileTo
 	ctxt.GetInner(ctx).PrivateKeyPath = savePrivateFileTo
 	return nil
@@ -129,7 +131,16 @@ func (s *SSHKeyGen) writeKeyToFile(keyBytes []byte, saveFileTo string) error {
 
 // Rollback implements the Task interface
 func (s *SSHKeyGen) Rollback(ctx context.Context) error {
-	return os.Remove(s.keypath)
+	if !s.generated {
+		return nil
+	}
+	for _, f := range []string{s.keypath, s.keypath + ".pub"} {
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			return errors.WithStack(err)
+		}
+	}
+	s.generated = false
+	return nil
 }
 
 // String implements the fmt.Stringer interface
@@ -80,7 +80,7 @@ func (s *Serial) Execute(ctx context.Context) error {
 				fmt.Printf("+ [ Serial ] - %s\n", t.String())
 			}
 		}
-		err := t.Execute(ctx)
+		err := executeTask(ctx, t)
 		if err != nil && !s.ignoreError {
 			return errors.WithStack(err)
 		}
@@ -90,14 +90,16 @@ func (s *Serial) Execute(ctx context.Context) error {
 
 // Rollback implements the Task interface
 func (s *Serial) Rollback(ctx context.Context) error {
-	// Rollback in reverse order
+	// Rollback in reverse order, and go on rolling back the others on
+	// error so that as many changes as possible are undone
+	var firstError error
 	for i := len(s.inner) - 1; i >= 0; i-- {
 		err := s.inner[i].Rollback(ctx)
-		if err != nil {
-			return errors.WithStack(err)
+		if err != nil && !stderrors.Is(err, ErrUnsupportedRollback) && firstError == nil {
+			firstError = err
 		}
 	}
-	return nil
+	return errors.WithStack(firstError)
 }
 
 // String implements the fmt.Stringer interface
@@ -135,7 +137,7 @@ func (pt *Parallel) Execute(ctx context.Context) error {
 					fmt.Printf("+ [Parallel] - %s\n", t.String())
 				}
 			}
-			err := t.Execute(ctx)
+			err := executeTask(ctx, t)
 			if err != nil {
 				mu.Lock()
 				if firstError == nil {
@@ -166,7 +168,7 @@ func (pt *Parallel) Rollback(ctx context.Context) error {
 		go func(ctx context.Context, t Task) {
 			defer wg.Done()
 			err := t.Rollback(ctx)
-			if err != nil {
+			if err != nil && !stderrors.Is(err, ErrUnsupportedRollback) {
 				mu.Lock()
 				if firstError == nil {
 					firstError = err
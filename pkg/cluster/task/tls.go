@@ -0,0 +1,112 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/openGemini/gemix/pkg/cluster/ctxt"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/crypto"
+	"github.com/openGemini/gemix/pkg/meta"
+	"github.com/pkg/errors"
+)
+
+// TLSCert generates a certificate for instance and transfers it to the server
+type TLSCert struct {
+	comp   string
+	role   string
+	host   string   // the host to transfer the certificate to
+	sans   []string // the addresses of the instance used as SANs
+	port   int
+	ca     *crypto.CertificateAuthority
+	caCert string // the local CA certificate file transferred with the certificate
+	paths  meta.DirPaths
+}
+
+// Execute implements the Task interface
+func (c *TLSCert) Execute(ctx context.Context) error {
+	privKey, err := crypto.NewKeyPair(crypto.KeyTypeRSA, crypto.KeySchemeRSASSAPSSSHA256)
+	if err != nil {
+		return err
+	}
+
+	// the loopback and the addresses of the instance are used as SANs
+	hosts := []string{"localhost"}
+	ips := []string{"127.0.0.1"}
+	for _, san := range c.sans {
+		if net.ParseIP(san) != nil {
+			ips = append(ips, san)
+		} else {
+			hosts = append(hosts, san)
+		}
+	}
+
+	csr, err := privKey.CSR(c.role, c.comp, hosts, ips)
+	if err != nil {
+		return err
+	}
+	certBytes, err := c.ca.Sign(csr)
+	if err != nil {
+		return err
+	}
+
+	// save the certificate and key to the cache dir before transferring
+	if err := os.MkdirAll(c.paths.Cache, 0750); err != nil {
+		return errors.WithMessagef(err, "create cache directory failed: %s", c.paths.Cache)
+	}
+	prefix := fmt.Sprintf("%s-%s-%d", c.comp, c.host, c.port)
+	certFile := filepath.Join(c.paths.Cache, prefix+".crt")
+	keyFile := filepath.Join(c.paths.Cache, prefix+".pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: certBytes,
+	}), 0644); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.WriteFile(keyFile, privKey.Pem(), 0600); err != nil {
+		return errors.WithStack(err)
+	}
+
+	e, ok := ctxt.GetInner(ctx).GetExecutor(c.host)
+	if !ok {
+		return ErrNoExecutor
+	}
+	for src, dst := range map[string]string{
+		certFile: spec.TLSCertPath(c.paths.Deploy, c.comp),
+		keyFile:  spec.TLSKeyPath(c.paths.Deploy, c.comp),
+		c.caCert: spec.TLSCAPath(c.paths.Deploy),
+	} {
+		if err := e.Transfer(ctx, src, dst, false, 0, false); err != nil {
+			return errors.WithMessagef(err, "failed to transfer %s to %s@%s", src, c.host, dst)
+		}
+	}
+	return nil
+}
+
+// Rollback implements the Task interface
+func (c *TLSCert) Rollback(ctx context.Context) error {
+	return ErrUnsupportedRollback
+}
+
+// String implements the fmt.Stringer interface
+func (c *TLSCert) String() string {
+	return fmt.Sprintf("TLSCert: host=%s role=%s cn=%s", c.host, c.role, c.comp)
+}
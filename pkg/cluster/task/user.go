@@ -17,6 +17,7 @@ package task
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/openGemini/gemix/pkg/cluster/ctxt"
 	"github.com/pkg/errors"
@@ -38,6 +39,14 @@ const (
 	userAddCmd  = "/usr/sbin/useradd"
 	userDelCmd  = "/usr/sbin/userdel"
 	groupAddCmd = "/usr/sbin/groupadd"
+	groupDelCmd = "/usr/sbin/groupdel"
+)
+
+// the markers printed when the user, group or sudoers file is created
+const (
+	userCreatedMarker   = "user-created"
+	groupCreatedMarker  = "group-created"
+	sudoerCreatedMarker = "sudoer-created"
 )
 
 // UserAction is used to create user or del user on the target host
@@ -50,6 +59,11 @@ type UserAction struct {
 	home           string // home directory of user
 	shell          string
 	sudoer         bool // when true, the user will be added to sudoers list
+
+	// the changes to be undone on rollback
+	userCreated   bool
+	groupCreated  bool
+	sudoerCreated bool
 }
 
 // Execute implements the Task interface
@@ -86,20 +100,21 @@ func (u *UserAction) Execute(ctx context.Context) error {
 		}
 
 		// groupadd -f <group-name>
-		groupAdd := fmt.Sprintf("%s -f %s", groupAddCmd, u.group)
+		groupAdd := fmt.Sprintf("(getent group %[2]s > /dev/null || echo %[3]s) && %[1]s -f %[2]s",
+			groupAddCmd, u.group, groupCreatedMarker)
 
 		// useradd -g <group-name> <user-name>
 		cmd = fmt.Sprintf("%s -g %s %s", cmd, u.group, u.name)
 
 		// prevent errors when username already in use
-		cmd = fmt.Sprintf("id -u %s > /dev/null 2>&1 || (%s && %s)", u.name, groupAdd, cmd)
+		cmd = fmt.Sprintf("id -u %s > /dev/null 2>&1 || (%s && %s && echo %s)", u.name, groupAdd, cmd, userCreatedMarker)
 
 		// add user to sudoers list
 		if u.sudoer {
 			sudoLine := fmt.Sprintf("%s ALL=(ALL) NOPASSWD:ALL",
 				u.name)
-			cmd = fmt.Sprintf("%s && %s",
-				cmd,
+			cmd = fmt.Sprintf("%s && (test -f /etc/sudoers.d/%s || echo %s) && %s",
+				cmd, u.name, sudoerCreatedMarker,
 				fmt.Sprintf("echo '%s' > /etc/sudoers.d/%s", sudoLine, u.name))
 		}
 
@@ -110,15 +125,48 @@ func (u *UserAction) Execute(ctx context.Context) error {
 		//	case UserActionModify:
 		//		cmd = usermodCmd
 	}
-	_, _, err := exec.Execute(ctx, cmd, true) // use root to create the dir
+	stdout, _, err := exec.Execute(ctx, cmd, true) // use root to create the dir
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	for _, line := range strings.Split(string(stdout), "\n") {
+		switch strings.TrimSpace(line) {
+		case userCreatedMarker:
+			u.userCreated = true
+		case groupCreatedMarker:
+			u.groupCreated = true
+		case sudoerCreatedMarker:
+			u.sudoerCreated = true
+		}
+	}
 	return nil
 }
 
 // Rollback implements the Task interface
 func (u *UserAction) Rollback(ctx context.Context) error {
+	var cmds []string
+	if u.sudoerCreated {
+		cmds = append(cmds, fmt.Sprintf("rm -f /etc/sudoers.d/%s", u.name))
+	}
+	if u.userCreated {
+		cmds = append(cmds, fmt.Sprintf("(%s -r %s || [ $? -eq 6 ])", userDelCmd, u.name))
+	}
+	if u.groupCreated {
+		// the group may have been removed with the user
+		cmds = append(cmds, fmt.Sprintf("(! getent group %[2]s > /dev/null || %[1]s %[2]s)", groupDelCmd, u.group))
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	exec, found := ctxt.GetInner(ctx).GetExecutor(u.host)
+	if !found {
+		panic(ErrNoExecutor)
+	}
+	if _, _, err := exec.Execute(ctx, strings.Join(cmds, " && "), true); err != nil {
+		return errors.WithStack(err)
+	}
+	u.sudoerCreated, u.userCreated, u.groupCreated = false, false, false
 	return nil
 }
 
@@ -27,13 +27,20 @@ import (
 var auditEnabled atomic.Bool
 var auditBuffer *bytes.Buffer
 var auditDir string
+var auditID string
 
 // EnableAuditLog enables audit log.
 func EnableAuditLog(dir string) {
 	auditDir = dir
+	auditID = audit.NewAuditID()
 	auditEnabled.Store(true)
 }
 
+// AuditID returns the ID of the audit log of current operation.
+func AuditID() string {
+	return auditID
+}
+
 // DisableAuditLog disables audit log.
 func DisableAuditLog() {
 	auditEnabled.Store(false)
@@ -55,7 +62,7 @@ func OutputAuditLogToFileIfEnabled(dir, fileSuffix string) error {
 		return err
 	}
 
-	err := audit.OutputAuditLog(dir, fileSuffix, auditBuffer.Bytes())
+	err := audit.OutputAuditLog(dir, auditID, fileSuffix, auditBuffer.Bytes())
 	if err != nil {
 		return err
 	}
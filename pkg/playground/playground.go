@@ -0,0 +1,506 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package playground
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/openGemini/gemix/pkg/base52"
+	"github.com/openGemini/gemix/pkg/cluster/spec"
+	"github.com/openGemini/gemix/pkg/cluster/task"
+	"github.com/openGemini/gemix/pkg/localdata"
+	logprinter "github.com/openGemini/gemix/pkg/logger/printer"
+	"github.com/openGemini/gemix/pkg/utils"
+	utils2 "github.com/openGemini/gemix/utils"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	metaFileName = "playground.yaml"
+	readyTimeout = 60 * time.Second
+	stopTimeout  = 10 * time.Second
+)
+
+// Options represents the options of a playground cluster
+type Options struct {
+	Version string
+	Tag     string // the data is kept after exit if the tag is specified
+	Host    string
+	Meta    int
+	Sql     int
+	Store   int
+	Monitor bool
+}
+
+// meta is saved in the data dir of a tagged playground, so that it can be
+// started again with the same ports and directories
+type meta struct {
+	Version  string              `yaml:"version"`
+	Topology *spec.Specification `yaml:"topology"`
+}
+
+// Playground runs a throwaway cluster on the local machine
+type Playground struct {
+	opt    Options
+	dir    string
+	binDir string
+	topo   *spec.Specification
+	procs  []*process
+	logger *logprinter.Logger
+}
+
+// NewPlayground creates a Playground.
+func NewPlayground(opt Options, logger *logprinter.Logger) *Playground {
+	return &Playground{opt: opt, logger: logger}
+}
+
+// Run deploys and starts the playground cluster, then blocks until
+// an interrupt signal is received.
+func (p *Playground) Run() error {
+	keep := p.opt.Tag != ""
+	if !keep {
+		p.opt.Tag = base52.Encode(time.Now().UnixNano() + rand.Int63n(1000))
+	}
+	p.dir = filepath.Join(gemixHome(), localdata.DataParentDir, p.opt.Tag)
+	p.binDir = filepath.Join(p.dir, "bin")
+
+	if !keep {
+		defer os.RemoveAll(p.dir)
+	}
+	if err := p.prepare(); err != nil {
+		return err
+	}
+
+	sc := make(chan os.Signal, 1)
+	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sc)
+
+	err := p.start(sc)
+	if err == nil {
+		p.printEndpoints()
+		<-sc
+		fmt.Println()
+	}
+
+	p.logger.Infof("Stopping the playground cluster...")
+	p.stop()
+	if err != nil {
+		return err
+	}
+	if keep {
+		p.logger.Infof("Data is kept in %s, run with `--tag %s` again to reuse it", p.dir, p.opt.Tag)
+	}
+	return nil
+}
+
+// prepare lays out the binaries and configs of all instances
+func (p *Playground) prepare() error {
+	metaFile := filepath.Join(p.dir, metaFileName)
+	if utils.IsExist(metaFile) {
+		m, err := loadMeta(metaFile)
+		if err != nil {
+			return err
+		}
+		if p.opt.Version != "" && p.opt.Version != m.Version {
+			return errors.Errorf("playground `%s` was created with version %s, but %s is specified", p.opt.Tag, m.Version, p.opt.Version)
+		}
+		p.opt.Version = m.Version
+		p.topo = m.Topology
+		p.logger.Infof("Reuse the playground `%s` in %s", p.opt.Tag, p.dir)
+	} else {
+		if p.opt.Version == "" {
+			latest, err := utils2.GetLatestVerFromCurl()
+			if err != nil {
+				return errors.WithMessage(err, "get the latest version")
+			}
+			if p.opt.Version, err = utils.FmtVer(latest); err != nil {
+				return err
+			}
+		}
+		topo, err := p.newTopology()
+		if err != nil {
+			return err
+		}
+		p.topo = topo
+	}
+
+	if err := utils.MkdirAll(p.dir, 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := p.installBinaries(); err != nil {
+		return err
+	}
+	for _, comp := range p.components() {
+		for _, inst := range comp.Instances() {
+			if err := p.initInstance(inst); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := yaml.Marshal(&meta{Version: p.opt.Version, Topology: p.topo})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(metaFile, data, 0644))
+}
+
+func loadMeta(file string) (*meta, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	m := &meta{}
+	if err = yaml.Unmarshal(data, m); err != nil {
+		return nil, errors.WithMessagef(err, "parse %s", file)
+	}
+	return m, nil
+}
+
+// newTopology generates the topology of the playground, each instance
+// gets its own directory and free ports on the playground host
+func (p *Playground) newTopology() (*spec.Specification, error) {
+	if p.opt.Meta < 1 || p.opt.Sql < 1 || p.opt.Store < 1 {
+		return nil, errors.New("at least one instance of ts-meta, ts-sql and ts-store is required")
+	}
+	ports, err := freePorts(p.opt.Host, p.opt.Meta*4+p.opt.Sql+p.opt.Store*3)
+	if err != nil {
+		return nil, err
+	}
+	next := func() int {
+		port := ports[0]
+		ports = ports[1:]
+		return port
+	}
+
+	topo := &spec.Specification{}
+	topo.GlobalOptions.DeployDir = p.dir
+	topo.GlobalOptions.LogDir = filepath.Join(p.dir, "logs")
+	topo.GlobalOptions.DataDir = filepath.Join(p.dir, "data")
+	for i := 0; i < p.opt.Meta; i++ {
+		dir := filepath.Join(p.dir, fmt.Sprintf("%s-%d", spec.ComponentTSMeta, i))
+		topo.TSMetaServers = append(topo.TSMetaServers, &spec.TSMetaSpec{
+			Host:       p.opt.Host,
+			DeployDir:  dir,
+			LogDir:     filepath.Join(dir, "logs"),
+			DataDir:    filepath.Join(dir, "data"),
+			ClientPort: next(),
+			PeerPort:   next(),
+			RaftPort:   next(),
+			GossipPort: next(),
+		})
+	}
+	for i := 0; i < p.opt.Store; i++ {
+		dir := filepath.Join(p.dir, fmt.Sprintf("%s-%d", spec.ComponentTSStore, i))
+		topo.TSStoreServers = append(topo.TSStoreServers, &spec.TSStoreSpec{
+			Host:       p.opt.Host,
+			DeployDir:  dir,
+			LogDir:     filepath.Join(dir, "logs"),
+			DataDir:    filepath.Join(dir, "data"),
+			IngestPort: next(),
+			SelectPort: next(),
+			GossipPort: next(),
+		})
+	}
+	for i := 0; i < p.opt.Sql; i++ {
+		dir := filepath.Join(p.dir, fmt.Sprintf("%s-%d", spec.ComponentTSSql, i))
+		topo.TSSqlServers = append(topo.TSSqlServers, &spec.TSSqlSpec{
+			Host:      p.opt.Host,
+			DeployDir: dir,
+			LogDir:    filepath.Join(dir, "logs"),
+			Port:      next(),
+		})
+	}
+	if p.opt.Monitor {
+		dir := filepath.Join(p.dir, spec.ComponentTSMonitor)
+		topo.MonitoredOptions = spec.TSMonitoredOptions{
+			TSMonitorEnabled: true,
+			DeployDir:        dir,
+			LogDir:           filepath.Join(dir, "logs"),
+		}
+	}
+	return topo, nil
+}
+
+// components returns the playground components in starting order
+func (p *Playground) components() []spec.Component {
+	comps := []spec.Component{
+		&spec.TSMetaComponent{Topology: p.topo},
+		&spec.TSStoreComponent{Topology: p.topo},
+		&spec.TSSqlComponent{Topology: p.topo},
+	}
+	if p.topo.MonitoredOptions.TSMonitorEnabled {
+		comps = append(comps, &spec.TSMonitorComponent{Topology: p.topo})
+	}
+	return comps
+}
+
+// installBinaries downloads the package to the local package cache and
+// extracts the binaries into the bin dir of the playground
+func (p *Playground) installBinaries() error {
+	if utils.IsExist(p.binDir) {
+		return nil
+	}
+
+	version := strings.TrimPrefix(p.opt.Version, "v")
+	err := task.NewDownloader(spec.ComponentOpenGemini, runtime.GOOS, runtime.GOARCH, p.opt.Version).Execute(context.Background())
+	if err != nil {
+		return err
+	}
+
+	pkg, err := os.Open(spec.PackagePath(spec.ComponentOpenGemini, version, runtime.GOOS, runtime.GOARCH))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer pkg.Close()
+
+	tmp := filepath.Join(p.dir, "package")
+	defer os.RemoveAll(tmp)
+	if err = utils.Untar(pkg, tmp); err != nil {
+		return errors.WithMessagef(err, "extract %s", pkg.Name())
+	}
+	// the binaries are packaged in usr/bin/ts-*
+	return errors.WithStack(os.Rename(filepath.Join(tmp, "usr", "bin"), p.binDir))
+}
+
+// initInstance creates the directories of the instance and generates its
+// config with the same default config as the cluster components
+func (p *Playground) initInstance(inst spec.Instance) error {
+	deployDir := inst.DeployDir()
+	for _, dir := range []string{deployDir, inst.LogDir(), filepath.Join(deployDir, "conf"), p.metricDir()} {
+		if err := utils.MkdirAll(dir, 0755); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	var global, conf map[string]any
+	switch ins := inst.(type) {
+	case *spec.TSMetaInstance:
+		global = p.topo.ServerConfigs.TsMeta
+		conf = ins.SetDefaultConfig(ins.InstanceSpec.(*spec.TSMetaSpec).Config)
+	case *spec.TSStoreInstance:
+		global = p.topo.ServerConfigs.TsStore
+		conf = ins.SetDefaultConfig(ins.InstanceSpec.(*spec.TSStoreSpec).Config)
+	case *spec.TSSqlInstance:
+		global = p.topo.ServerConfigs.TsSql
+		conf = ins.SetDefaultConfig(ins.InstanceSpec.(*spec.TSSqlSpec).Config)
+	case *spec.TSMonitorInstance:
+		global = p.topo.ServerConfigs.TsMonitor
+		conf = ins.SetDefaultConfig(ins.InstanceSpec.(*spec.TSMonitorSpec).Config, "playground_"+p.opt.Tag)
+		conf["monitor.metric-path"] = p.metricDir()
+	default:
+		return errors.Errorf("unsupported instance %s", inst.ID())
+	}
+	// all the instances share one metric dir, so that a single ts-monitor can collect them
+	if p.topo.MonitoredOptions.TSMonitorEnabled && inst.ComponentName() != spec.ComponentTSMonitor {
+		conf["monitor.store-path"] = filepath.Join(p.metricDir(), fmt.Sprintf("%s-%d-metric.data", inst.ComponentName(), inst.GetPort()))
+	}
+
+	data, err := spec.Merge2Toml(inst.ComponentName(), global, conf)
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(deployDir, "conf", inst.ComponentName()+".toml")
+	return errors.WithStack(os.WriteFile(file, data, 0644))
+}
+
+func (p *Playground) metricDir() string {
+	return filepath.Join(p.dir, "metric")
+}
+
+// start launches the instances one by one in starting order and waits
+// for each of them to be ready
+func (p *Playground) start(sc <-chan os.Signal) error {
+	for _, comp := range p.components() {
+		for _, inst := range comp.Instances() {
+			p.logger.Infof("Starting %s %s", inst.ComponentName(), inst.ID())
+			proc, err := startProcess(p.binDir, inst)
+			if err != nil {
+				return err
+			}
+			p.procs = append(p.procs, proc)
+
+			if err = p.waitReady(proc, sc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// waitReady waits until the ports of the instance are reachable
+func (p *Playground) waitReady(proc *process, sc <-chan os.Signal) error {
+	ports := readyPorts(proc.inst)
+	deadline := time.Now().Add(readyTimeout)
+	for {
+		select {
+		case <-proc.done:
+			return errors.Errorf("%s exited unexpectedly: %v, see logs in %s", proc.inst.ID(), proc.err, proc.inst.LogDir())
+		case <-sc:
+			return errors.New("interrupted")
+		default:
+		}
+
+		ready := true
+		for _, port := range ports {
+			conn, err := net.DialTimeout("tcp", utils.JoinHostPort(proc.inst.GetHost(), port), time.Second)
+			if err != nil {
+				ready = false
+				break
+			}
+			_ = conn.Close()
+		}
+		if ready {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("%s is not ready after %s, see logs in %s", proc.inst.ID(), readyTimeout, proc.inst.LogDir())
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// readyPorts returns the ports which have to be listened on when the instance is ready
+func readyPorts(inst spec.Instance) []int {
+	switch s := inst.(type) {
+	case *spec.TSMetaInstance:
+		return []int{s.InstanceSpec.(*spec.TSMetaSpec).ClientPort}
+	case *spec.TSStoreInstance:
+		return []int{s.InstanceSpec.(*spec.TSStoreSpec).SelectPort}
+	case *spec.TSSqlInstance:
+		return []int{s.InstanceSpec.(*spec.TSSqlSpec).Port}
+	}
+	return nil
+}
+
+// stop terminates the instances in reverse starting order
+func (p *Playground) stop() {
+	for i := len(p.procs) - 1; i >= 0; i-- {
+		p.procs[i].stop()
+	}
+	p.procs = nil
+}
+
+func (p *Playground) printEndpoints() {
+	fmt.Println()
+	color.Green("openGemini playground `%s` is started", p.opt.Tag)
+	for _, s := range p.topo.TSSqlServers {
+		fmt.Printf("Connect openGemini:  %s\n", color.CyanString("ts-cli --host %s --port %d", s.Host, s.Port))
+		fmt.Printf("HTTP endpoint:       %s\n", color.CyanString("http://%s", utils.JoinHostPort(s.Host, s.Port)))
+	}
+	for _, s := range p.topo.TSMetaServers {
+		fmt.Printf("ts-meta endpoint:    %s\n", color.CyanString("http://%s", utils.JoinHostPort(s.Host, s.ClientPort)))
+	}
+	if p.topo.MonitoredOptions.TSMonitorEnabled {
+		fmt.Printf("Monitor database:    %s\n", color.CyanString("playground_%s", p.opt.Tag))
+	}
+	fmt.Printf("Data directory:      %s\n", p.dir)
+	fmt.Println("Press Ctrl-C to stop the playground")
+}
+
+// freePorts returns n distinct free ports on the host
+func freePorts(host string, n int) ([]int, error) {
+	listeners := make([]net.Listener, 0, n)
+	defer func() {
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+	}()
+
+	ports := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		l, err := net.Listen("tcp", utils.JoinHostPort(host, 0))
+		if err != nil {
+			return nil, errors.WithMessagef(err, "allocate port on %s", host)
+		}
+		listeners = append(listeners, l)
+		ports = append(ports, l.Addr().(*net.TCPAddr).Port)
+	}
+	return ports, nil
+}
+
+// gemixHome returns the home directory of gemix
+func gemixHome() string {
+	return localdata.InitProfile().Root()
+}
+
+// process is a running instance of the playground
+type process struct {
+	inst spec.Instance
+	cmd  *exec.Cmd
+	log  *os.File
+	done chan struct{}
+	err  error
+}
+
+// startProcess launches the instance as a child process, the same way
+// as the run script of the cluster components
+func startProcess(binDir string, inst spec.Instance) (*process, error) {
+	comp := inst.ComponentName()
+	log, err := os.OpenFile(filepath.Join(inst.LogDir(), strings.TrimPrefix(comp, "ts-")+"_extra.log"),
+		os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	cmd := exec.Command(filepath.Join(binDir, comp), "--config=conf/"+comp+".toml")
+	cmd.Dir = inst.DeployDir()
+	cmd.Env = append(os.Environ(), "GODEBUG=madvdontneed=1")
+	cmd.Stdout = log
+	cmd.Stderr = log
+	if err = cmd.Start(); err != nil {
+		_ = log.Close()
+		return nil, errors.WithMessagef(err, "start %s", inst.ID())
+	}
+
+	proc := &process{inst: inst, cmd: cmd, log: log, done: make(chan struct{})}
+	go func() {
+		proc.err = cmd.Wait()
+		_ = log.Close()
+		close(proc.done)
+	}()
+	return proc, nil
+}
+
+// stop terminates the process gracefully, it is killed if it does not
+// exit in time
+func (p *process) stop() {
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+
+	_ = p.cmd.Process.Signal(syscall.SIGTERM)
+	select {
+	case <-p.done:
+	case <-time.After(stopTimeout):
+		_ = p.cmd.Process.Kill()
+		<-p.done
+	}
+}
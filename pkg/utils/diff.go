@@ -0,0 +1,69 @@
+// Copyright 2023 Huawei Cloud Computing Technologies Co., Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// ShowDiff writes the colored unified diff of t1 and t2 to w
+func ShowDiff(t1 string, t2 string, w io.Writer) error {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:       difflib.SplitLines(t1),
+		B:       difflib.SplitLines(t2),
+		Context: 3,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			fmt.Fprint(w, color.GreenString(line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Fprint(w, color.RedString(line))
+		case strings.HasPrefix(line, "@@"):
+			fmt.Fprint(w, color.CyanString(line))
+		default:
+			fmt.Fprint(w, line)
+		}
+	}
+	return nil
+}
+
+// OpenFileInEditor opens the file in the editor specified by $EDITOR, vi is used by default
+func OpenFileInEditor(filename string) error {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// the editor may contain arguments, e.g. "code --wait"
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], filename)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return errors.WithStack(cmd.Run())
+}
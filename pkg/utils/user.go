@@ -26,3 +26,12 @@ func UserHome() string {
 	}
 	return u.HomeDir
 }
+
+// CurrentUser returns current login user
+func CurrentUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return "root"
+	}
+	return u.Username
+}